@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
 )
 
@@ -18,6 +20,22 @@ func (cc *configClient) ServerAddress() string {
 	return cc.c.Client.ServerAddress
 }
 
+func (cc *configClient) MaxRetries() int {
+	return cc.c.Client.MaxRetries
+}
+
+func (cc *configClient) RetryBaseDelay() time.Duration {
+	return time.Duration(cc.c.Client.RetryBaseDelay) * time.Millisecond
+}
+
+func (cc *configClient) RetryMaxDelay() time.Duration {
+	return time.Duration(cc.c.Client.RetryMaxDelay) * time.Millisecond
+}
+
+func (cc *configClient) Handshake() bool {
+	return cc.c.Client.Handshake
+}
+
 func newConfigService(c *config.Config) *configService {
 	return &configService{
 		c: c,
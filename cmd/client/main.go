@@ -4,19 +4,48 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/calibrate"
 	"github.com/kamilkn/pow-tcp-server-client/internal/app/client"
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/loadtest"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/log"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
 )
 
 func main() {
-	configuration, err := config.Parse("config")
+	if len(os.Args) > 1 && os.Args[1] == calibrate.Command {
+		if err := calibrate.Run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Println(err.Error()) //nolint:forbidigo // print error.
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == loadtest.Command {
+		if err := loadtest.RunCommand(os.Args[2:], os.Stdout, newLoadTestService); err != nil {
+			fmt.Println(err.Error()) //nolint:forbidigo // print error.
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	flags := config.ParseFlags("config")
+
+	configuration, err := config.Check(flags.Path, config.ValidateClient)
 	if err != nil {
 		fmt.Println(err.Error()) //nolint:forbidigo // print error.
 		os.Exit(1)
 	}
 
+	if flags.CheckConfig {
+		fmt.Println("config is valid") //nolint:forbidigo // print result.
+
+		return
+	}
+
 	configService := newConfigService(configuration)
 	configClient := newConfigClient(configuration)
 
@@ -26,12 +55,19 @@ func main() {
 	})
 
 	mainService := service.NewClient(service.ClientOpts{
-		Config: configService,
-		Logger: logger,
+		Config:    configService,
+		Logger:    logger,
+		Schemes:   newSchemes(),
+		Progress:  newProgressLine(os.Stderr),
+		Handshake: configClient.Handshake(),
 	})
 
 	logger.Debug("client configured",
 		"server_address", configClient.ServerAddress(),
+		"max_retries", configClient.MaxRetries(),
+		"retry_base_delay", configClient.RetryBaseDelay(),
+		"retry_max_delay", configClient.RetryMaxDelay(),
+		"handshake", configClient.Handshake(),
 		"puzzle_compute_max_attempts", configService.PuzzleComputeMaxAttempts(),
 	)
 
@@ -45,3 +81,22 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newSchemes - returns puzzle schemes supported by client in preference order.
+func newSchemes() *puzzle.Registry {
+	return puzzle.NewRegistry(
+		puzzle.NewTimeLockClientScheme(),
+		puzzle.NewSubPuzzleScheme(nil),
+		puzzle.NewHashcashScheme(nil, nil),
+	)
+}
+
+// newLoadTestService - returns service for honest load test clients, logs are discarded.
+func newLoadTestService(c *config.Config) loadtest.Service {
+	return service.NewClient(service.ClientOpts{
+		Config:    newConfigService(c),
+		Logger:    log.New(log.Opts{Level: log.LevelError + 1}),
+		Schemes:   newSchemes(),
+		Handshake: c.Client.Handshake,
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+)
+
+// newProgressLine - returns callback printing solving progress as one updating line,
+// nil if output is not a terminal.
+func newProgressLine(f *os.File) puzzle.ProgressFunc {
+	if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	return func(p puzzle.Progress) {
+		printProgress(f, p)
+	}
+}
+
+func printProgress(w io.Writer, p puzzle.Progress) {
+	line := fmt.Sprintf("solving puzzle: %s attempts, %s/s, elapsed %s, eta %s",
+		humanize(float64(p.Attempts)), humanize(p.HashRate), p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+
+	if p.Done {
+		line = fmt.Sprintf("puzzle solved: %s attempts, %s/s, elapsed %s",
+			humanize(float64(p.Attempts)), humanize(p.HashRate), p.Elapsed.Round(time.Millisecond))
+	}
+
+	// \r and clear line to update progress in place.
+	_, _ = fmt.Fprint(w, "\r\033[K"+line)
+
+	if p.Done {
+		_, _ = fmt.Fprintln(w)
+	}
+}
+
+// humanize - format number with k, M, G suffixes.
+func humanize(n float64) string {
+	const base = 1000
+
+	for _, suffix := range []string{"", "k", "M"} {
+		if n < base {
+			return fmt.Sprintf("%.1f%s", n, suffix)
+		}
+
+		n /= base
+	}
+
+	return fmt.Sprintf("%.1fG", n)
+}
@@ -1,47 +1,221 @@
 package main
 
 import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 )
 
-func newConfigServer(c *config.Config) *configServer {
+const randomSigningKeyLength = 32
+
+// validateConfig - check server config and rules of puzzle schemes, returns all found problems at once.
+func validateConfig(c *config.Config) error {
+	errs := []error{config.ValidateServer(c)}
+
+	if count := c.Hashcash.SubPuzzles; count > 0 &&
+		(!puzzle.IsSubPuzzlesCountValid(count) || puzzle.SubPuzzleBits(c.Hashcash.Bits, count) <= 0) {
+		errs = append(errs, fmt.Errorf("hashcash.sub_puzzles: %w", puzzle.ErrIncorrectSubPuzzles))
+	}
+
+	if bits := c.Hashcash.TimeLockModulusBits; bits < puzzle.MinTimeLockModulusBits || bits > puzzle.MaxTimeLockModulusBits {
+		errs = append(errs, fmt.Errorf("hashcash.timelock_modulus_bits: %w", config.ErrValueOutOfRange))
+	}
+
+	return errors.Join(errs...)
+}
+
+func newConfigServer(h *config.Holder) *configServer {
 	return &configServer{
-		c: c,
+		h: h,
 	}
 }
 
 type configServer struct {
-	c *config.Config
+	h *config.Holder
 }
 
 func (cc *configServer) Address() string {
-	return cc.c.Server.Address
+	return cc.h.Load().Server.Address
 }
 
 func (cc *configServer) ShutdownTimeout() time.Duration {
-	return time.Duration(cc.c.Server.ShutdownTimeout) * time.Millisecond
+	return time.Duration(cc.h.Load().Server.ShutdownTimeout) * time.Millisecond
 }
 
 func (cc *configServer) ConnectionTimeout() time.Duration {
-	return time.Duration(cc.c.Server.ConnectionTimeout) * time.Millisecond
+	return time.Duration(cc.h.Load().Server.ConnectionTimeout) * time.Millisecond
+}
+
+func (cc *configServer) ReadTimeout() time.Duration {
+	return time.Duration(cc.h.Load().Server.ReadTimeout) * time.Millisecond
+}
+
+func (cc *configServer) Workers() int {
+	return cc.h.Load().Server.Workers
+}
+
+func (cc *configServer) QueueSize() int {
+	return cc.h.Load().Server.QueueSize
+}
+
+func (cc *configServer) QueueTimeout() time.Duration {
+	return time.Duration(cc.h.Load().Server.QueueTimeout) * time.Millisecond
+}
+
+func (cc *configServer) BusyRetryAfter() time.Duration {
+	return time.Duration(cc.h.Load().Server.BusyRetryAfter) * time.Millisecond
 }
 
-func newConfigService(c *config.Config) *configService {
-	return &configService{
-		c: c,
+func (cc *configServer) AdminAddress() string {
+	return cc.h.Load().Server.AdminAddress
+}
+
+func (cc *configServer) ResourcesFile() string {
+	return cc.h.Load().Server.ResourcesFile
+}
+
+func (cc *configServer) PuzzleClearInterval() time.Duration {
+	if cc.h.Load().Server.PuzzleClearInterval == 0 {
+		return time.Duration(cc.h.Load().Hashcash.TTL) * time.Millisecond
+	}
+
+	return time.Duration(cc.h.Load().Server.PuzzleClearInterval) * time.Millisecond
+}
+
+func (cc *configServer) ViolationBanThreshold() int {
+	return cc.h.Load().Server.ViolationBanThreshold
+}
+
+func (cc *configServer) ViolationWindow() time.Duration {
+	return time.Duration(cc.h.Load().Server.ViolationWindow) * time.Millisecond
+}
+
+func (cc *configServer) WatchInterval() time.Duration {
+	return time.Duration(cc.h.Load().Server.WatchInterval) * time.Millisecond
+}
+
+func newConfigService(h *config.Holder) (*configService, error) {
+	cs := &configService{
+		h: h,
 	}
+
+	if _, err := cs.loadAlgorithm(); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
 }
 
 type configService struct {
-	c *config.Config
+	h         *config.Holder
+	algorithm atomic.Pointer[configAlgorithm]
+}
+
+// configAlgorithm - puzzle algorithm built from config.
+type configAlgorithm struct {
+	config    *config.Config
+	algorithm hashcash.Algorithm
 }
 
 func (cs *configService) PuzzleTTL() time.Duration {
-	return time.Duration(cs.c.Hashcash.TTL) * time.Millisecond
+	return time.Duration(cs.h.Load().Hashcash.TTL) * time.Millisecond
+}
+
+func (cs *configService) PuzzleClockSkew() time.Duration {
+	return time.Duration(cs.h.Load().Hashcash.ClockSkew) * time.Millisecond
 }
 
 func (cs *configService) PuzzleZeroBits() int {
-	return cs.c.Hashcash.Bits
+	return cs.h.Load().Hashcash.Bits
+}
+
+// PuzzleAlgorithm - returns puzzle algorithm, it's built once per loaded config.
+// Config is validated before it's stored, so algorithm is always built,
+// otherwise algorithm of previous config is kept.
+func (cs *configService) PuzzleAlgorithm() hashcash.Algorithm {
+	algorithm, err := cs.loadAlgorithm()
+	if err != nil {
+		return cs.algorithm.Load().algorithm
+	}
+
+	return algorithm
+}
+
+// loadAlgorithm - returns algorithm of current config, builds it if config is changed.
+func (cs *configService) loadAlgorithm() (hashcash.Algorithm, error) {
+	c := cs.h.Load()
+	if cached := cs.algorithm.Load(); cached != nil && cached.config == c {
+		return cached.algorithm, nil
+	}
+
+	algorithm, err := config.NewAlgorithm(c.Hashcash)
+	if err != nil {
+		return nil, fmt.Errorf("puzzle algorithm: %w", err)
+	}
+
+	cs.algorithm.Store(&configAlgorithm{config: c, algorithm: algorithm})
+
+	return algorithm, nil
+}
+
+func (cs *configService) PuzzleSubPuzzles() int {
+	return cs.h.Load().Hashcash.SubPuzzles
+}
+
+func (cs *configService) PuzzleTimeLockIterations() int {
+	return cs.h.Load().Hashcash.TimeLockIterations
+}
+
+func (cs *configService) PuzzleTimeLockModulusBits() int {
+	return cs.h.Load().Hashcash.TimeLockModulusBits
+}
+
+// PuzzleSigner - returns signer with configured key, random key is generated if key isn't set.
+func (cs *configService) PuzzleSigner() (*hashcash.Signer, error) {
+	c := cs.h.Load().Hashcash
+	key := []byte(c.SigningKey)
+
+	if len(key) == 0 {
+		key = make([]byte, randomSigningKeyLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate signing key: %w", err)
+		}
+	}
+
+	return hashcash.NewSigner(c.SigningKeyID, key), nil
+}
+
+func (cs *configService) MaxPuzzlesPerConnection() int {
+	return cs.h.Load().Server.MaxPuzzlesPerConnection
+}
+
+func (cs *configService) PuzzleVerifyConcurrency() int {
+	if cs.h.Load().Hashcash.VerifyConcurrency == 0 {
+		return runtime.NumCPU()
+	}
+
+	return cs.h.Load().Hashcash.VerifyConcurrency
+}
+
+func (cs *configService) SetPuzzle(bits *int, ttl *time.Duration) error {
+	return cs.h.Update(func(c *config.Config) {
+		if bits != nil {
+			c.Hashcash.Bits = *bits
+		}
+
+		if ttl != nil {
+			c.Hashcash.TTL = int(ttl.Milliseconds())
+		}
+	})
+}
+
+func (cs *configService) Effective() any {
+	return cs.h.Load()
 }
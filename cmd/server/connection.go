@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/admin"
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/server"
+)
+
+func newConnectionsAdmin(s *server.Server) *connectionsAdmin {
+	return &connectionsAdmin{
+		s: s,
+	}
+}
+
+type connectionsAdmin struct {
+	s *server.Server
+}
+
+func (ca *connectionsAdmin) Connections() []admin.Connection {
+	infos := ca.s.Connections()
+
+	conns := make([]admin.Connection, 0, len(infos))
+	for _, info := range infos {
+		conns = append(conns, admin.Connection{
+			ClientID:    info.ClientID,
+			State:       info.State,
+			ConnectedAt: info.ConnectedAt,
+		})
+	}
+
+	return conns
+}
+
+func (ca *connectionsAdmin) Kick(ip string) int {
+	return ca.s.Kick(ip)
+}
@@ -7,73 +7,195 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/admin"
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/calibrate"
 	"github.com/kamilkn/pow-tcp-server-client/internal/app/server"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/banlist"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/log"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/penalty"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/tcp"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == calibrate.Command {
+		if err := calibrate.Run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Println(err.Error()) //nolint:forbidigo // print error.
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
-	configuration, err := config.Parse("config")
+	flags := config.ParseFlags("config")
+
+	configuration, err := config.Check(flags.Path, validateConfig)
+	if err != nil {
+		fmt.Println(err.Error()) //nolint:forbidigo // print error.
+		os.Exit(1)
+	}
+
+	if flags.CheckConfig {
+		fmt.Println("config is valid") //nolint:forbidigo // print result.
+
+		return
+	}
+
+	configHolder := config.NewHolder(configuration, validateConfig)
+	configService, err := newConfigService(configHolder)
 	if err != nil {
 		fmt.Println(err.Error()) //nolint:forbidigo // print error.
 		os.Exit(1)
 	}
 
-	configService := newConfigService(configuration)
-	configServer := newConfigServer(configuration)
+	configServer := newConfigServer(configHolder)
 
 	logger := log.New(log.Opts{
 		Level: log.Level(configuration.Server.LogLevel),
 		JSON:  configuration.Server.LogJSON,
 	})
 
-	puzzleCache := cache.New[string, struct{}](ctx, cache.Opts{
-		CleanInterval: configService.PuzzleTTL(),
+	puzzleCache := cache.New[string, int](ctx, cache.Opts{
+		CleanInterval: configServer.PuzzleClearInterval(),
 		Logger:        logger,
 	})
 
 	resourceCache := cache.New[int, string](ctx, cache.Opts{
 		Logger: logger,
 	})
-	for i, r := range resources {
-		resourceCache.Add(i, r)
+
+	resourceLoader := newResourceLoader(resourceCache, configServer)
+	if _, err = resourceLoader.Reload(); err != nil {
+		fmt.Println(err.Error()) //nolint:forbidigo // print error.
+		os.Exit(1)
 	}
 
+	bans := banlist.New()
+
+	penalties := penalty.New(penalty.Opts{
+		BanThreshold: configServer.ViolationBanThreshold(),
+		Window:       configServer.ViolationWindow(),
+		Banlist:      bans,
+	})
+
+	schemes := puzzle.NewRegistry()
+	if configService.PuzzleTimeLockIterations() > 0 {
+		timeLockScheme, err := puzzle.NewTimeLockScheme(configService, configService.PuzzleTimeLockModulusBits())
+		if err != nil {
+			fmt.Println(err.Error()) //nolint:forbidigo // print error.
+			os.Exit(1)
+		}
+
+		schemes.Register(timeLockScheme)
+	}
+
+	if configService.PuzzleSubPuzzles() > 0 {
+		schemes.Register(puzzle.NewSubPuzzleScheme(configService))
+	}
+
+	signer, err := configService.PuzzleSigner()
+	if err != nil {
+		fmt.Println(err.Error()) //nolint:forbidigo // print error.
+		os.Exit(1)
+	}
+
+	schemes.Register(puzzle.NewHashcashScheme(configService, signer))
+
 	mainService := service.NewServer(&service.ServerOpts{
 		Config:        configService,
 		Logger:        logger,
 		PuzzleCache:   puzzleCache,
 		ResourceCache: resourceCache,
 		ErrorChecker:  tcp.NewConnErrorChecker(),
+		Schemes:       schemes,
+		Penalties:     penalties,
 	})
 
 	mainServer, err := server.Listen(ctx, server.Opts{
 		Config:  configServer,
 		Logger:  logger,
 		Service: mainService,
+		Banlist: bans,
 	})
 	if err != nil {
 		fmt.Println(err.Error()) //nolint:forbidigo // print error.
 		os.Exit(1)
 	}
 
+	var adminServer *admin.Server
+	if configServer.AdminAddress() != "" {
+		adminServer, err = admin.Listen(admin.Opts{
+			Config:      configServer,
+			Logger:      logger,
+			Settings:    configService,
+			Connections: newConnectionsAdmin(mainServer),
+			Banlist:     bans,
+			PuzzleCache: puzzleCache,
+			Resources:   resourceLoader,
+			Violations:  penalties,
+		})
+		if err != nil {
+			fmt.Println(err.Error()) //nolint:forbidigo // print error.
+			os.Exit(1)
+		}
+	}
+
 	logger.Debug("server started",
 		"address", configServer.Address(),
 		"shutdown_timeout", configServer.ShutdownTimeout(),
 		"connection_timeout", configServer.ConnectionTimeout(),
+		"read_timeout", configServer.ReadTimeout(),
+		"workers", configServer.Workers(),
+		"queue_size", configServer.QueueSize(),
+		"queue_timeout", configServer.QueueTimeout(),
+		"busy_retry_after", configServer.BusyRetryAfter(),
+		"admin_address", configServer.AdminAddress(),
+		"config_watch_interval", configServer.WatchInterval(),
+		"puzzle_clear_interval", configServer.PuzzleClearInterval(),
+		"violation_ban_threshold", configServer.ViolationBanThreshold(),
+		"violation_window", configServer.ViolationWindow(),
 		"puzzle_ttl", configService.PuzzleTTL(),
+		"puzzle_clock_skew", configService.PuzzleClockSkew(),
 		"puzzle_zero_bits", configService.PuzzleZeroBits(),
+		"puzzle_algorithm", configService.PuzzleAlgorithm().ID(),
+		"puzzle_sub_puzzles", configService.PuzzleSubPuzzles(),
+		"puzzle_timelock_iterations", configService.PuzzleTimeLockIterations(),
+		"puzzle_signing_key_id", signer.KeyID(),
+		"puzzle_schemes", schemes.IDs(),
+		"puzzle_verify_concurrency", configService.PuzzleVerifyConcurrency(),
 	)
 
+	reloader := newConfigReloader(configHolder, flags.Path, logger)
+	if flags.Path != "" && configServer.WatchInterval() > 0 {
+		go config.Watch(ctx, flags.Path, configServer.WatchInterval(), reloader.Reload)
+	}
+
 	signalChannel := make(chan os.Signal, 1)
-	signal.Notify(signalChannel, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signalChannel, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for sig := range signalChannel {
+		if sig != syscall.SIGHUP {
+			break
+		}
+
+		logger.Info("reloading config")
+		reloader.Reload()
+	}
 
-	<-signalChannel
 	cancel()
-	mainServer.Shutdown()
+
+	if adminServer != nil {
+		if err = adminServer.Shutdown(context.Background()); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
+	report := mainServer.Shutdown()
+	logger.Info("server stopped", "drained", report.Drained, "killed", report.Killed,
+		"leftover", report.Leftover)
 }
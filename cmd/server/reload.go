@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
+)
+
+func newConfigReloader(h *config.Holder, path string, logger *slog.Logger) *configReloader {
+	return &configReloader{
+		h:      h,
+		path:   path,
+		logger: logger,
+	}
+}
+
+// configReloader - reloads config from file or env and swaps it if valid.
+// Fields which could not be changed live keep old values and are reported as requiring restart.
+type configReloader struct {
+	h      *config.Holder
+	path   string
+	logger *slog.Logger
+	mu     sync.Mutex
+}
+
+func (r *configReloader) Reload() {
+	const operationName = "configReloader.Reload"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newConfig, err := config.Check(r.path, validateConfig)
+	if err != nil {
+		r.logger.Error("config is not valid, reload skipped", "operationName", operationName, "error", err.Error())
+
+		return
+	}
+
+	oldConfig := r.h.Load()
+
+	for _, change := range config.Diff(oldConfig, newConfig) {
+		if change.Restart {
+			r.logger.Warn("config change requires restart", "field", change.Field, "old", change.Old, "new", change.New)
+
+			continue
+		}
+
+		r.logger.Info("config changed", "field", change.Field, "old", change.Old, "new", change.New)
+	}
+
+	r.h.Store(config.KeepRestartFields(oldConfig, newConfig))
+}
@@ -1,5 +1,13 @@
 package main
 
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
+)
+
 var resources = []string{ //nolint:gochecknoglobals // not need
 	`For instance, on the planet Earth, man had always assumed that he was more intelligent than dolphins because he had achieved so much—the wheel, New York, wars and so on—whilst all the dolphins had ever done was muck about in the water having a good time. But conversely, the dolphins had always believed that they were far more intelligent than man—for precisely the same reasons.`,
 	`He felt that his whole life was some kind of dream and he sometimes wondered whose it was and whether they were enjoying it.`,
@@ -44,3 +52,46 @@ var resources = []string{ //nolint:gochecknoglobals // not need
 	`There is a moment in every dawn when light floats, there is the possibility of magic. Creation holds its breath.`,
 	`In the beginning the Universe was created. This has made a lot of people very angry and been widely regarded as a bad move.`,
 }
+
+func newResourceLoader(resourceCache *cache.Cache[int, string], cs *configServer) *resourceLoader {
+	return &resourceLoader{
+		cache:  resourceCache,
+		config: cs,
+	}
+}
+
+// resourceLoader - loads resources from file, one resource per line, or built-in resources if file is not set.
+type resourceLoader struct {
+	cache  *cache.Cache[int, string]
+	config *configServer
+}
+
+func (l *resourceLoader) Reload() (int, error) {
+	loaded := resources
+
+	if path := l.config.ResourcesFile(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("read resources: %w", err)
+		}
+
+		loaded = nil
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				loaded = append(loaded, line)
+			}
+		}
+	}
+
+	for i, r := range loaded {
+		l.cache.Add(i, r)
+	}
+
+	for _, k := range l.cache.Keys() {
+		if k >= len(loaded) {
+			l.cache.Delete(k)
+		}
+	}
+
+	return len(loaded), nil
+}
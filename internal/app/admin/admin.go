@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	unixPrefix        = "unix:"
+	readHeaderTimeout = 5 * time.Second
+)
+
+// Listen - start admin http server on loopback address or unix socket.
+// Address format - host:port or unix:/path/to/socket.
+func Listen(opts Opts) (*Server, error) {
+	listener, err := listen(opts.Config.AdminAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		listener: listener,
+		logger:   opts.Logger,
+	}
+
+	server.http = &http.Server{
+		Handler:           newHandler(opts),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go server.serve()
+
+	return server, nil
+}
+
+// Opts - options to run admin server.
+type Opts struct {
+	Config      Config
+	Logger      Logger
+	Settings    Settings
+	Connections Connections
+	Banlist     Banlist
+	PuzzleCache PuzzleCache
+	Resources   Resources
+	Violations  Violations
+}
+
+// Server - admin http server.
+type Server struct {
+	listener net.Listener
+	http     *http.Server
+	logger   Logger
+}
+
+// Addr - returns listening address.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Shutdown - shutdown admin server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.http.Shutdown(ctx); err != nil {
+		return fmt.Errorf("admin shutdown: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) serve() {
+	const operationName = "admin.Server.serve"
+
+	if err := s.http.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error(err.Error(), "operationName", operationName)
+	}
+}
+
+func listen(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, unixPrefix); ok {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("remove unix socket: %w", err)
+		}
+
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("unix listen: %w", err)
+		}
+
+		return listener, nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("admin address: %w", err)
+	}
+
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, ErrAddressNotLocal
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("TCP listen: %w", err)
+	}
+
+	return listener, nil
+}
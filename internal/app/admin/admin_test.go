@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/banlist"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/penalty"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConfig struct {
+	address string
+}
+
+func (c *mockConfig) AdminAddress() string { return c.address }
+
+type mockLogger struct{}
+
+func (l *mockLogger) Info(_ string, _ ...any)  {}
+func (l *mockLogger) Error(_ string, _ ...any) {}
+
+type mockSettings struct {
+	Bits int `json:"bits"`
+	TTL  int `json:"ttl"`
+}
+
+func (s *mockSettings) Effective() any { return s }
+
+func (s *mockSettings) SetPuzzle(bits *int, ttl *time.Duration) error {
+	if ttl != nil && *ttl <= 0 {
+		return errors.New("ttl: value must be positive")
+	}
+
+	if bits != nil {
+		s.Bits = *bits
+	}
+
+	if ttl != nil {
+		s.TTL = int(ttl.Milliseconds())
+	}
+
+	return nil
+}
+
+type mockConnections struct {
+	kicked string
+}
+
+func (c *mockConnections) Connections() []Connection {
+	return []Connection{{ClientID: "127.0.0.1:1234", State: "idle", ConnectedAt: time.Unix(0, 0).UTC()}}
+}
+
+func (c *mockConnections) Kick(ip string) int {
+	c.kicked = ip
+
+	return 1
+}
+
+type mockPuzzleCache struct{}
+
+func (c *mockPuzzleCache) Stats() cache.Stats { return cache.Stats{Size: 3, Expired: 1} }
+
+type mockViolations struct{}
+
+func (v *mockViolations) Stats() penalty.Stats {
+	return penalty.Stats{
+		Total:   map[string]int64{"protocol violation": 3},
+		Clients: map[string]int{"10.0.0.1": 2},
+	}
+}
+
+type mockResources struct{}
+
+func (r *mockResources) Reload() (int, error) { return 42, nil }
+
+func Test_Handler(t *testing.T) {
+	settings := &mockSettings{Bits: 5, TTL: 1000}
+	connections := &mockConnections{}
+
+	srv := httptest.NewServer(newHandler(Opts{
+		Logger:      &mockLogger{},
+		Settings:    settings,
+		Connections: connections,
+		Banlist:     banlist.New(),
+		PuzzleCache: &mockPuzzleCache{},
+		Resources:   &mockResources{},
+		Violations:  &mockViolations{},
+	}))
+	defer srv.Close()
+
+	do := func(t *testing.T, method, path, body string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		return res.StatusCode, string(data)
+	}
+
+	t.Run("config ok", func(t *testing.T) {
+		status, body := do(t, http.MethodPatch, "/config/hashcash", `{"bits":7}`)
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"bits":7,"ttl":1000}`, body)
+
+		status, body = do(t, http.MethodGet, "/config", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"bits":7,"ttl":1000}`, body)
+
+		status, _ = do(t, http.MethodPatch, "/config/hashcash", `bits`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = do(t, http.MethodPatch, "/config/hashcash", `{"bits":9,"ttl":0}`)
+		require.Equal(t, http.StatusBadRequest, status)
+
+		status, body = do(t, http.MethodGet, "/config", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"bits":7,"ttl":1000}`, body)
+	})
+
+	t.Run("connections ok", func(t *testing.T) {
+		status, body := do(t, http.MethodGet, "/connections", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `[{"client_id":"127.0.0.1:1234","state":"idle","connected_at":"1970-01-01T00:00:00Z"}]`, body)
+
+		status, body = do(t, http.MethodDelete, "/connections/127.0.0.1", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"kicked":1}`, body)
+		require.Equal(t, "127.0.0.1", connections.kicked)
+	})
+
+	t.Run("bans ok", func(t *testing.T) {
+		status, body := do(t, http.MethodPost, "/bans?prefix=10.0.0.0/8", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `["10.0.0.0/8"]`, body)
+
+		status, body = do(t, http.MethodDelete, "/bans?prefix=10.0.0.0/8", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `[]`, body)
+
+		status, body = do(t, http.MethodPost, "/bans?prefix=host", "")
+		require.Equal(t, http.StatusBadRequest, status)
+		require.JSONEq(t, `{"error":"incorrect ip prefix"}`, body)
+	})
+
+	t.Run("cache and resources ok", func(t *testing.T) {
+		status, body := do(t, http.MethodGet, "/cache/puzzles", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"size":3,"expired":1}`, body)
+
+		status, body = do(t, http.MethodGet, "/violations", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"total":{"protocol violation":3},"clients":{"10.0.0.1":2}}`, body)
+
+		status, body = do(t, http.MethodPost, "/resources/reload", "")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"resources":42}`, body)
+	})
+}
+
+func Test_Listen(t *testing.T) {
+	t.Run("listen on loopback ok", func(t *testing.T) {
+		srv, err := Listen(Opts{Config: &mockConfig{address: "127.0.0.1:0"}, Logger: &mockLogger{}})
+		require.NoError(t, err)
+		require.NoError(t, srv.Shutdown(context.Background()))
+	})
+
+	t.Run("listen on public address failed", func(t *testing.T) {
+		_, err := Listen(Opts{Config: &mockConfig{address: "0.0.0.0:0"}, Logger: &mockLogger{}})
+		require.ErrorIs(t, err, ErrAddressNotLocal)
+
+		_, err = Listen(Opts{Config: &mockConfig{address: ":0"}, Logger: &mockLogger{}})
+		require.ErrorIs(t, err, ErrAddressNotLocal)
+	})
+}
@@ -0,0 +1,8 @@
+package admin
+
+import "errors"
+
+var (
+	ErrAddressNotLocal  = errors.New("admin address must be loopback or unix socket")
+	ErrIncorrectRequest = errors.New("incorrect request")
+)
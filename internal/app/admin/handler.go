@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Connection - active connection description.
+type Connection struct {
+	ClientID    string    `json:"client_id"`
+	State       string    `json:"state"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// hashcashSettings - hashcash settings to change, nil fields are not changed.
+// TTL in ms.
+type hashcashSettings struct {
+	Bits *int `json:"bits"`
+	TTL  *int `json:"ttl"`
+}
+
+func newHandler(opts Opts) http.Handler {
+	h := &handler{opts: opts}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /config", h.getConfig)
+	mux.HandleFunc("PATCH /config/hashcash", h.patchHashcash)
+	mux.HandleFunc("GET /connections", h.getConnections)
+	mux.HandleFunc("DELETE /connections/{ip}", h.kickConnections)
+	mux.HandleFunc("GET /bans", h.getBans)
+	mux.HandleFunc("POST /bans", h.ban)
+	mux.HandleFunc("DELETE /bans", h.unban)
+	mux.HandleFunc("GET /cache/puzzles", h.getPuzzleCacheStats)
+	mux.HandleFunc("POST /resources/reload", h.reloadResources)
+	mux.HandleFunc("GET /violations", h.getViolations)
+
+	return mux
+}
+
+type handler struct {
+	opts Opts
+}
+
+func (h *handler) getConfig(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.opts.Settings.Effective())
+}
+
+func (h *handler) patchHashcash(w http.ResponseWriter, r *http.Request) {
+	var settings hashcashSettings
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil || json.Unmarshal(body, &settings) != nil {
+		h.writeError(w, http.StatusBadRequest, ErrIncorrectRequest)
+
+		return
+	}
+
+	var ttl *time.Duration
+	if settings.TTL != nil {
+		ttl = new(time.Duration)
+		*ttl = time.Duration(*settings.TTL) * time.Millisecond
+	}
+
+	if err = h.opts.Settings.SetPuzzle(settings.Bits, ttl); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if settings.Bits != nil {
+		h.opts.Logger.Info("puzzle zero bits changed by admin", "bits", *settings.Bits)
+	}
+
+	if settings.TTL != nil {
+		h.opts.Logger.Info("puzzle ttl changed by admin", "ttl", *settings.TTL)
+	}
+
+	h.writeJSON(w, http.StatusOK, h.opts.Settings.Effective())
+}
+
+func (h *handler) getConnections(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.opts.Connections.Connections())
+}
+
+func (h *handler) kickConnections(w http.ResponseWriter, r *http.Request) {
+	kicked := h.opts.Connections.Kick(r.PathValue("ip"))
+	h.writeJSON(w, http.StatusOK, map[string]int{"kicked": kicked})
+}
+
+func (h *handler) getBans(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.opts.Banlist.Prefixes())
+}
+
+func (h *handler) ban(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if err := h.opts.Banlist.Ban(prefix); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	h.opts.Logger.Info("prefix banned by admin", "prefix", prefix)
+	h.writeJSON(w, http.StatusOK, h.opts.Banlist.Prefixes())
+}
+
+func (h *handler) unban(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if err := h.opts.Banlist.Unban(prefix); err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	h.opts.Logger.Info("prefix unbanned by admin", "prefix", prefix)
+	h.writeJSON(w, http.StatusOK, h.opts.Banlist.Prefixes())
+}
+
+func (h *handler) getPuzzleCacheStats(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.opts.PuzzleCache.Stats())
+}
+
+func (h *handler) getViolations(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.opts.Violations.Stats())
+}
+
+func (h *handler) reloadResources(w http.ResponseWriter, _ *http.Request) {
+	count, err := h.opts.Resources.Reload()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	h.opts.Logger.Info("resources reloaded by admin", "count", count)
+	h.writeJSON(w, http.StatusOK, map[string]int{"resources": count})
+}
+
+func (h *handler) writeJSON(w http.ResponseWriter, status int, v any) {
+	const operationName = "admin.handler.writeJSON"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.opts.Logger.Error(err.Error(), "operationName", operationName)
+	}
+}
+
+func (h *handler) writeError(w http.ResponseWriter, status int, err error) {
+	h.writeJSON(w, status, map[string]string{"error": err.Error()})
+}
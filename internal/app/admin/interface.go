@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/penalty"
+)
+
+// Config - config interface.
+type Config interface {
+	AdminAddress() string
+}
+
+// Logger - logger interface.
+type Logger interface {
+	Info(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Settings - runtime server settings.
+type Settings interface {
+	Effective() any
+	// SetPuzzle - change puzzle zero bits and ttl at once, nil values are not changed.
+	// Nothing is changed if any value is incorrect.
+	SetPuzzle(bits *int, ttl *time.Duration) error
+}
+
+// Connections - active connections manager.
+type Connections interface {
+	Connections() []Connection
+	Kick(ip string) int
+}
+
+// Banlist - banned ip prefixes manager.
+type Banlist interface {
+	Ban(prefix string) error
+	Unban(prefix string) error
+	Prefixes() []string
+}
+
+// PuzzleCache - puzzle cache interface.
+type PuzzleCache interface {
+	Stats() cache.Stats
+}
+
+// Violations - client violations counter interface.
+type Violations interface {
+	Stats() penalty.Stats
+}
+
+// Resources - resources loader interface.
+type Resources interface {
+	Reload() (count int, err error)
+}
@@ -0,0 +1,151 @@
+package calibrate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+// Command - calibrate subcommand name.
+const Command = "calibrate"
+
+// Percentiles of solve time in report.
+const (
+	P50 = 0.5
+	P90 = 0.9
+	P99 = 0.99
+)
+
+const (
+	// measureBits - difficulty which is never solved during measuring.
+	measureBits = 64
+	// ttlFactor - recommended ttl is p99 solve time multiplied by factor.
+	ttlFactor = 2
+)
+
+// Opts - calibration options.
+// Duration - min duration of hash rate measuring.
+// Target - target solve time to recommend bits and ttl.
+// MinBits, MaxBits - range of difficulties in report.
+type Opts struct {
+	Algorithm hashcash.Algorithm
+	Duration  time.Duration
+	Target    time.Duration
+	MinBits   int
+	MaxBits   int
+}
+
+// Report - calibration report, times are in milliseconds.
+type Report struct {
+	Algorithm    string         `json:"algorithm"`
+	HashRate     float64        `json:"hash_rate"`
+	Target       float64        `json:"target_ms"`
+	Difficulties []Difficulty   `json:"difficulties"`
+	Recommended  Recommendation `json:"recommended"`
+}
+
+// Difficulty - expected and percentile solve times for bits.
+type Difficulty struct {
+	Bits             int     `json:"bits"`
+	ExpectedAttempts float64 `json:"expected_attempts"`
+	Expected         float64 `json:"expected_ms"`
+	P50              float64 `json:"p50_ms"`
+	P90              float64 `json:"p90_ms"`
+	P99              float64 `json:"p99_ms"`
+}
+
+// Recommendation - recommended hashcash config values for target solve time.
+// Bits - max bits with expected solve time not more than target,
+// TTL - p99 solve time multiplied by 2 rounded up to seconds, in milliseconds as in config.
+type Recommendation struct {
+	Bits           int `json:"bits"`
+	TTL            int `json:"ttl"`
+	ClientHashRate int `json:"client_hash_rate"`
+}
+
+// MeasureHashRate - returns hash rate of algorithm with real hashcash code in attempts per second.
+// Number of attempts is doubled until measuring takes duration.
+func MeasureHashRate(algorithm hashcash.Algorithm, duration time.Duration) (float64, error) {
+	h, err := hashcash.NewWithAlgorithm(measureBits, Command, algorithm, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("new hashcash: %w", err)
+	}
+
+	for attempts := 1; ; attempts *= 2 {
+		start := time.Now()
+
+		err = h.Compute(attempts)
+		if err != nil && !errors.Is(err, hashcash.ErrComputingMaxAttemptsExceeded) {
+			return 0, fmt.Errorf("compute hashcash: %w", err)
+		}
+
+		if elapsed := time.Since(start); elapsed >= duration {
+			// counter from 0 to attempts inclusive.
+			return float64(attempts+1) / elapsed.Seconds(), nil
+		}
+	}
+}
+
+// NewReport - returns report for measured hash rate.
+func NewReport(algorithm string, hashRate float64, opts Opts) Report {
+	report := Report{
+		Algorithm: algorithm,
+		HashRate:  hashRate,
+		Target:    milliseconds(opts.Target),
+		Recommended: Recommendation{
+			Bits:           opts.MinBits,
+			ClientHashRate: int(hashRate),
+		},
+	}
+
+	for bits := opts.MinBits; bits <= opts.MaxBits; bits++ {
+		report.Difficulties = append(report.Difficulties, Difficulty{
+			Bits:             bits,
+			ExpectedAttempts: hashcash.ExpectedAttempts(bits),
+			Expected:         milliseconds(ExpectedSolveTime(bits, hashRate)),
+			P50:              milliseconds(SolveTime(bits, hashRate, P50)),
+			P90:              milliseconds(SolveTime(bits, hashRate, P90)),
+			P99:              milliseconds(SolveTime(bits, hashRate, P99)),
+		})
+	}
+
+	for bits := opts.MinBits; bits <= opts.MaxBits; bits++ {
+		if ExpectedSolveTime(bits, hashRate) <= opts.Target {
+			report.Recommended.Bits = bits
+		}
+	}
+
+	ttl := max(math.Ceil(ttlFactor*SolveTime(report.Recommended.Bits, hashRate, P99).Seconds()), 1)
+	report.Recommended.TTL = int(ttl) * int(time.Second.Milliseconds())
+
+	return report
+}
+
+// ExpectedSolveTime - returns expected solve time of hashcash with bits at hash rate.
+func ExpectedSolveTime(bits int, hashRate float64) time.Duration {
+	return seconds(hashcash.ExpectedAttempts(bits) / hashRate)
+}
+
+// SolveTime - returns time to solve hashcash with bits at hash rate with probability quantile.
+// Number of attempts is geometrically distributed with success probability 16^-bits.
+func SolveTime(bits int, hashRate, quantile float64) time.Duration {
+	p := 1 / hashcash.ExpectedAttempts(bits)
+	attempts := math.Ceil(math.Log1p(-quantile) / math.Log1p(-p))
+
+	return seconds(attempts / hashRate)
+}
+
+func seconds(s float64) time.Duration {
+	if s*float64(time.Second) >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(s * float64(time.Second))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
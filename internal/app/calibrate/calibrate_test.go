@@ -0,0 +1,64 @@
+package calibrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+func Test_SolveTime(t *testing.T) {
+	t.Run("percentiles of geometric distribution", func(t *testing.T) {
+		// 1 bit - success probability 1/16, median is ceil(ln(0.5) / ln(15/16)) = 11 attempts.
+		require.Equal(t, 11*time.Second, SolveTime(1, 1, P50))
+		require.Equal(t, 36*time.Second, SolveTime(1, 1, P90))
+		require.Equal(t, 72*time.Second, SolveTime(1, 1, P99))
+		require.Equal(t, 16*time.Second, ExpectedSolveTime(1, 1))
+	})
+}
+
+func Test_NewReport(t *testing.T) {
+	t.Run("bits and ttl recommended", func(t *testing.T) {
+		report := NewReport("sha256", 1048576, Opts{Target: time.Second, MinBits: 1, MaxBits: 6})
+
+		require.Len(t, report.Difficulties, 6)
+		require.InDelta(t, 1000, report.Difficulties[4].Expected, 0.001)
+		require.Equal(t, Recommendation{Bits: 5, TTL: 10000, ClientHashRate: 1048576}, report.Recommended)
+	})
+
+	t.Run("min bits recommended for too short target", func(t *testing.T) {
+		report := NewReport("sha256", 1, Opts{Target: time.Second, MinBits: 2, MaxBits: 3})
+		require.Equal(t, 2, report.Recommended.Bits)
+	})
+}
+
+func Test_Run(t *testing.T) {
+	t.Run("json report ok", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := Run([]string{"-json", "-duration", "10ms", "-min-bits", "2", "-max-bits", "3"}, &out)
+		require.NoError(t, err)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		require.Equal(t, hashcash.AlgorithmSHA256, report.Algorithm)
+		require.Positive(t, report.HashRate)
+		require.Len(t, report.Difficulties, 2)
+	})
+
+	t.Run("text report ok", func(t *testing.T) {
+		var out bytes.Buffer
+
+		require.NoError(t, Run([]string{"-duration", "10ms", "-max-bits", "2"}, &out))
+		require.Contains(t, out.String(), "recommended for target 1s")
+	})
+
+	t.Run("incorrect options", func(t *testing.T) {
+		require.ErrorIs(t, Run([]string{"-min-bits", "3", "-max-bits", "2"}, &bytes.Buffer{}), ErrIncorrectOpts)
+		require.Error(t, Run([]string{"-unknown"}, &bytes.Buffer{}))
+	})
+}
@@ -0,0 +1,7 @@
+package calibrate
+
+import "errors"
+
+var (
+	ErrIncorrectOpts = errors.New("bits range, target and duration must be more than zero")
+)
@@ -0,0 +1,102 @@
+package calibrate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
+)
+
+// Run - run calibrate subcommand with command line args and print report to w.
+// Algorithm and its parameters are taken from config.
+func Run(args []string, w io.Writer) error {
+	var (
+		fs       = flag.NewFlagSet(Command, flag.ContinueOnError)
+		path     = fs.String("config", "", "config file path")
+		jsonOut  = fs.Bool("json", false, "print report as json")
+		opts     Opts
+		minBits  = 1
+		maxBits  = 8
+		target   = time.Second
+		duration = time.Second
+	)
+
+	fs.SetOutput(w)
+	fs.DurationVar(&opts.Target, "target", target, "target solve time")
+	fs.DurationVar(&opts.Duration, "duration", duration, "hash rate measuring duration")
+	fs.IntVar(&opts.MinBits, "min-bits", minBits, "min bits in report")
+	fs.IntVar(&opts.MaxBits, "max-bits", maxBits, "max bits in report")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	if opts.MinBits <= 0 || opts.MaxBits < opts.MinBits || opts.Target <= 0 || opts.Duration <= 0 {
+		return ErrIncorrectOpts
+	}
+
+	c, err := config.Load(*path)
+	if err != nil {
+		return err //nolint:wrapcheck // config error.
+	}
+
+	opts.Algorithm, err = config.NewAlgorithm(c.Hashcash)
+	if err != nil {
+		return fmt.Errorf("hashcash.algorithm: %w", err)
+	}
+
+	hashRate, err := MeasureHashRate(opts.Algorithm, opts.Duration)
+	if err != nil {
+		return err
+	}
+
+	report := NewReport(opts.Algorithm.ID(), hashRate, opts)
+
+	if *jsonOut {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report) //nolint:wrapcheck // encode error.
+	}
+
+	return printReport(w, report)
+}
+
+func printReport(w io.Writer, report Report) error {
+	fmt.Fprintf(w, "algorithm: %s\nhash rate: %.0f/s\n\n", report.Algorithm, report.HashRate)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd // padding.
+	fmt.Fprintln(tw, "bits\texpected attempts\texpected\tp50\tp90\tp99")
+
+	for _, d := range report.Difficulties {
+		fmt.Fprintf(tw, "%d\t%.0f\t%s\t%s\t%s\t%s\n",
+			d.Bits, d.ExpectedAttempts, duration(d.Expected), duration(d.P50), duration(d.P90), duration(d.P99))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("print report: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w, "\nrecommended for target %s: bits=%d ttl=%d client_hash_rate=%d\n",
+		duration(report.Target), report.Recommended.Bits, report.Recommended.TTL, report.Recommended.ClientHashRate)
+
+	return err //nolint:wrapcheck // print error.
+}
+
+// duration - format milliseconds as rounded duration.
+func duration(ms float64) string {
+	d := time.Duration(ms * float64(time.Millisecond))
+
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.String()
+	}
+}
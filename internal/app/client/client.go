@@ -1,8 +1,10 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"time"
 )
 
 // Opts - connection options.
@@ -12,10 +14,46 @@ type Opts struct {
 	Service Service
 }
 
+// retryAfterHinter - error with server hint when request could be retried.
+type retryAfterHinter interface {
+	RetryAfterHint() time.Duration
+}
+
 // Connect - connect to server.
+// Request failed with a retryable error is retried by retry policy from config.
+// Delay is exponential backoff with jitter, but not less than server retry-after hint.
 func Connect(opts Opts) error {
 	const operationName = "client.Connect"
 
+	policy := NewRetryPolicy(opts.Config)
+
+	for attempt := 0; ; attempt++ {
+		err := request(opts)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= policy.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		delay := policy.Delay(attempt)
+
+		var hinter retryAfterHinter
+		if errors.As(err, &hinter) {
+			delay = max(delay, hinter.RetryAfterHint())
+		}
+
+		opts.Logger.Warn("request failed, retrying", "operationName", operationName,
+			"error", err.Error(), "attempt", attempt+1, "delay", delay)
+
+		time.Sleep(delay)
+	}
+}
+
+func request(opts Opts) error {
+	const operationName = "client.request"
+
 	conn, err := net.Dial("tcp", opts.Config.ServerAddress())
 	if err != nil {
 		opts.Logger.Error(err.Error(), "operationName", operationName)
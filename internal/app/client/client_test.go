@@ -0,0 +1,164 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
+)
+
+type configMock struct {
+	address    string
+	maxRetries int
+}
+
+func (c configMock) ServerAddress() string         { return c.address }
+func (c configMock) MaxRetries() int               { return c.maxRetries }
+func (c configMock) RetryBaseDelay() time.Duration { return time.Millisecond }
+func (c configMock) RetryMaxDelay() time.Duration  { return 4 * time.Millisecond }
+
+type loggerMock struct{}
+
+func (loggerMock) Info(string, ...any)  {}
+func (loggerMock) Warn(string, ...any)  {}
+func (loggerMock) Error(string, ...any) {}
+func (loggerMock) Debug(string, ...any) {}
+
+// serviceMock - returns errors in order, then success.
+type serviceMock struct {
+	errs  []error
+	calls int
+}
+
+func (s *serviceMock) RequestResource(string, io.ReadWriter) (string, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return "", s.errs[s.calls-1]
+	}
+
+	return "resource", nil
+}
+
+// serverError - returns error received from server in error message.
+func serverError(err error) error {
+	return service.ErrCheckResMessage(message.Message{Command: message.CommandError, Payload: err.Error()})
+}
+
+func listen(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			conn.Close()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	t.Run("exponential delay up to max", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+		require.Equal(t, 100*time.Millisecond, policy.Delay(0))
+		require.Equal(t, 200*time.Millisecond, policy.Delay(1))
+		require.Equal(t, 800*time.Millisecond, policy.Delay(3))
+		require.Equal(t, time.Second, policy.Delay(4))
+		require.Equal(t, time.Second, policy.Delay(100))
+	})
+
+	t.Run("jitter reduces delay", func(t *testing.T) {
+		policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: DefaultJitter}
+
+		for range 100 {
+			delay := policy.Delay(1)
+			require.GreaterOrEqual(t, delay, 100*time.Millisecond)
+			require.LessOrEqual(t, delay, 200*time.Millisecond)
+		}
+	})
+}
+
+func Test_IsRetryable(t *testing.T) {
+	t.Run("retryable", func(t *testing.T) {
+		for _, err := range []error{
+			serverError(service.ErrHashcashExpirationExceeded),
+			serverError(service.ErrTimeoutExceeded),
+			serverError(service.ErrHashcashHeaderNotFound),
+			serverError(service.ErrServerShuttingDown),
+			&service.RetryAfterError{Err: service.ErrServerBusy, RetryAfter: time.Second},
+			fmt.Errorf("RequestResource: %w", puzzle.ErrSolveMaxAttemptsExceeded),
+			io.EOF,
+			&net.OpError{Op: "dial", Err: timeoutError{}},
+		} {
+			require.True(t, IsRetryable(err), err.Error())
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		for _, err := range []error{
+			serverError(service.ErrHashcashHeaderNotCorrect),
+			serverError(service.ErrUnsupportedPuzzleScheme),
+			serverError(errors.New("unknown server error")),
+			service.ErrResponseCommandNotcorrect,
+			puzzle.ErrUnknownScheme,
+		} {
+			require.False(t, IsRetryable(err), err.Error())
+		}
+	})
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func Test_Connect(t *testing.T) {
+	t.Run("retryable errors retried", func(t *testing.T) {
+		srv := &serviceMock{errs: []error{
+			serverError(service.ErrHashcashExpirationExceeded),
+			&service.RetryAfterError{Err: service.ErrServerBusy, RetryAfter: time.Millisecond},
+		}}
+
+		err := Connect(Opts{Config: configMock{address: listen(t), maxRetries: 2}, Logger: loggerMock{}, Service: srv})
+		require.NoError(t, err)
+		require.Equal(t, 3, srv.calls)
+	})
+
+	t.Run("max retries exceeded", func(t *testing.T) {
+		srv := &serviceMock{errs: []error{
+			serverError(service.ErrTimeoutExceeded),
+			serverError(service.ErrTimeoutExceeded),
+		}}
+
+		err := Connect(Opts{Config: configMock{address: listen(t), maxRetries: 1}, Logger: loggerMock{}, Service: srv})
+		require.ErrorIs(t, err, service.ErrTimeoutExceeded)
+		require.Equal(t, 2, srv.calls)
+	})
+
+	t.Run("not retryable error returned", func(t *testing.T) {
+		srv := &serviceMock{errs: []error{serverError(service.ErrHashcashHeaderNotCorrect)}}
+
+		err := Connect(Opts{Config: configMock{address: listen(t), maxRetries: 3}, Logger: loggerMock{}, Service: srv})
+		require.ErrorIs(t, err, service.ErrHashcashHeaderNotCorrect)
+		require.Equal(t, 1, srv.calls)
+	})
+}
@@ -2,10 +2,14 @@ package client
 
 import (
 	"io"
+	"time"
 )
 
 type Config interface {
 	ServerAddress() string
+	MaxRetries() int
+	RetryBaseDelay() time.Duration
+	RetryMaxDelay() time.Duration
 }
 
 type Logger interface {
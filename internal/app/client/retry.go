@@ -0,0 +1,74 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
+)
+
+// RetryPolicy - policy to retry failed requests.
+// MaxRetries - max number of retries, 0 - no retries.
+// BaseDelay - delay before the first retry, it's doubled on every next retry up to MaxDelay.
+// Jitter - random part of delay from 0 to 1, delay is reduced by a random value up to Jitter*delay.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Jitter     float64
+}
+
+// DefaultJitter - default random part of retry delay.
+const DefaultJitter = 0.5
+
+// NewRetryPolicy - returns retry policy configured by client config.
+func NewRetryPolicy(c Config) RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: c.MaxRetries(),
+		BaseDelay:  c.RetryBaseDelay(),
+		MaxDelay:   c.RetryMaxDelay(),
+		Jitter:     DefaultJitter,
+	}
+}
+
+// Delay - returns delay before retry, retry starts from 0.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, p.MaxDelay)
+
+	if p.Jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay)) //nolint:gosec // jitter.
+	}
+
+	return delay
+}
+
+// IsRetryable - reports whether request failed with err could succeed if repeated with a new connection and puzzle.
+// These are retryable server errors (e.g. expired puzzle or busy server), network errors and unlucky puzzle solving.
+func IsRetryable(err error) bool {
+	var netErr net.Error
+
+	switch {
+	case service.IsRetryable(err):
+		return true
+	case errors.Is(err, puzzle.ErrSolveMaxAttemptsExceeded), errors.Is(err, hashcash.ErrComputingMaxAttemptsExceeded):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return true
+	case errors.As(err, &netErr):
+		return netErr.Timeout()
+	default:
+		return false
+	}
+}
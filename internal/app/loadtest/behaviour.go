@@ -0,0 +1,199 @@
+package loadtest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
+)
+
+// Virtual client behaviours.
+const (
+	BehaviourHonest      = "honest"       // solve puzzle with service client.
+	BehaviourBadSolution = "bad-solution" // send puzzle back without solving.
+	BehaviourNeverSolve  = "never-solve"  // request puzzle and wait until server closes connection.
+	BehaviourSlowloris   = "slowloris"    // send request byte by byte and never finish it.
+	BehaviourGarbage     = "garbage"      // send random bytes.
+)
+
+// Outcomes which are not server error messages.
+const (
+	OutcomeResource         = "resource"
+	OutcomeConnectionClosed = "connection closed"
+	OutcomeTimeout          = "timeout"
+
+	// outcomeDialPrefix - prefix of outcome of failed dial.
+	outcomeDialPrefix = "dial: "
+)
+
+const (
+	garbageLength     = 64
+	slowlorisInterval = time.Second
+)
+
+// behaviourFunc - run one request on connection and returns outcome,
+// resource, server error message or local error.
+type behaviourFunc func(conn net.Conn, service Service) string
+
+// behaviours - behaviours by name.
+var behaviours = map[string]behaviourFunc{ //nolint:gochecknoglobals // constant.
+	BehaviourHonest:      honest,
+	BehaviourBadSolution: badSolution,
+	BehaviourNeverSolve:  neverSolve,
+	BehaviourSlowloris:   slowloris,
+	BehaviourGarbage:     garbage,
+}
+
+func honest(conn net.Conn, srv Service) string {
+	_, err := srv.RequestResource(conn.LocalAddr().String(), conn)
+	if err != nil {
+		return errorOutcome(err)
+	}
+
+	return OutcomeResource
+}
+
+func badSolution(conn net.Conn, _ Service) string {
+	r := bufio.NewReader(conn)
+
+	msg, outcome := request(conn, r, message.Message{
+		Command: message.CommandRequestPuzzle,
+		Payload: puzzle.SchemeHashcash,
+	})
+	if msg.Command != message.CommandResponsePuzzle {
+		return outcome
+	}
+
+	_, outcome = request(conn, r, message.Message{
+		Command: message.CommandRequestResource,
+		Payload: msg.Payload,
+	})
+
+	return outcome
+}
+
+func neverSolve(conn net.Conn, _ Service) string {
+	r := bufio.NewReader(conn)
+
+	msg, outcome := request(conn, r, message.Message{
+		Command: message.CommandRequestPuzzle,
+		Payload: puzzle.SchemeHashcash,
+	})
+	if msg.Command != message.CommandResponsePuzzle {
+		return outcome
+	}
+
+	_, outcome = read(r)
+
+	return outcome
+}
+
+func slowloris(conn net.Conn, _ Service) string {
+	outcome := make(chan string, 1)
+
+	go func() {
+		_, o := read(bufio.NewReader(conn))
+		outcome <- o
+	}()
+
+	payload := []byte("1:" + puzzle.SchemeHashcash)
+	ticker := time.NewTicker(slowlorisInterval)
+
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		b := byte('x')
+		if i < len(payload) {
+			b = payload[i]
+		}
+
+		if _, err := conn.Write([]byte{b}); err != nil {
+			return <-outcome
+		}
+
+		select {
+		case o := <-outcome:
+			return o
+		case <-ticker.C:
+		}
+	}
+}
+
+func garbage(conn net.Conn, _ Service) string {
+	payload := make([]byte, garbageLength)
+	if _, err := rand.Read(payload); err != nil {
+		return err.Error()
+	}
+
+	for i, b := range payload {
+		if b == message.DelimiterMessage {
+			payload[i] = ' '
+		}
+	}
+
+	if _, err := conn.Write(append(payload, message.DelimiterMessage)); err != nil {
+		return errorOutcome(err)
+	}
+
+	_, outcome := read(bufio.NewReader(conn))
+
+	return outcome
+}
+
+// request - write message and read response.
+func request(w io.Writer, r *bufio.Reader, msg message.Message) (message.Message, string) {
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return message.Message{}, errorOutcome(err)
+	}
+
+	return read(r)
+}
+
+// read - read message and returns its outcome.
+func read(r *bufio.Reader) (message.Message, string) {
+	raw, err := r.ReadString(message.DelimiterMessage)
+	if err != nil {
+		return message.Message{}, errorOutcome(err)
+	}
+
+	msg, err := message.ParseMessage(raw)
+	if err != nil {
+		return message.Message{}, err.Error()
+	}
+
+	switch msg.Command {
+	case message.CommandError:
+		return msg, errorOutcome(service.ErrCheckResMessage(msg))
+	case message.CommandResponseResource:
+		return msg, OutcomeResource
+	default:
+		return msg, ""
+	}
+}
+
+// errorOutcome - returns server error message or local error class.
+func errorOutcome(err error) string {
+	var (
+		serverErr *service.ServerError
+		netErr    net.Error
+	)
+
+	switch {
+	case errors.As(err, &serverErr):
+		return serverErr.Message
+	case errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return OutcomeConnectionClosed
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return OutcomeTimeout
+	default:
+		return err.Error()
+	}
+}
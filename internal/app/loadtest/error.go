@@ -0,0 +1,9 @@
+package loadtest
+
+import "errors"
+
+var (
+	ErrIncorrectOpts    = errors.New("clients and timeout must be more than zero, duration or requests must be set")
+	ErrIncorrectMix     = errors.New("incorrect behaviours mix, format - behaviour=weight,behaviour=weight")
+	ErrUnknownBehaviour = errors.New("unknown behaviour")
+)
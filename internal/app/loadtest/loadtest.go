@@ -0,0 +1,306 @@
+package loadtest
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Command - load test subcommand name.
+const Command = "loadtest"
+
+// Service - service interface to request resource as honest client.
+type Service interface {
+	RequestResource(clientID string, rw io.ReadWriter) (string, error)
+}
+
+// Opts - load test options.
+// Clients - number of concurrent virtual clients.
+// Duration - test duration, Requests - total number of requests, test stops by the first reached limit, 0 - no limit.
+// RampUp - clients are started evenly during ramp-up.
+// Timeout - max duration of one request.
+// Mix - behaviours weights.
+type Opts struct {
+	Address  string
+	Service  Service
+	Clients  int
+	Duration time.Duration
+	Requests int
+	RampUp   time.Duration
+	Timeout  time.Duration
+	Mix      Mix
+}
+
+// Mix - behaviours with weights.
+type Mix []Weight
+
+// Weight - behaviour weight.
+type Weight struct {
+	Behaviour string
+	Weight    int
+}
+
+// ParseMix - parse behaviours mix, format - behaviour=weight,behaviour=weight.
+func ParseMix(s string) (Mix, error) {
+	var mix Mix
+
+	for _, pair := range strings.Split(s, ",") {
+		behaviour, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, ErrIncorrectMix
+		}
+
+		if _, ok = behaviours[behaviour]; !ok {
+			return nil, ErrUnknownBehaviour
+		}
+
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, ErrIncorrectMix
+		}
+
+		mix = append(mix, Weight{Behaviour: behaviour, Weight: w})
+	}
+
+	if mix.total() == 0 {
+		return nil, ErrIncorrectMix
+	}
+
+	return mix, nil
+}
+
+// String - returns mix in ParseMix format.
+func (m Mix) String() string {
+	pairs := make([]string, 0, len(m))
+	for _, w := range m {
+		pairs = append(pairs, w.Behaviour+"="+strconv.Itoa(w.Weight))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (m Mix) total() int {
+	total := 0
+	for _, w := range m {
+		total += w.Weight
+	}
+
+	return total
+}
+
+// pick - returns random behaviour by weights.
+func (m Mix) pick() string {
+	n := rand.IntN(m.total()) //nolint:gosec // behaviour choice.
+
+	for _, w := range m {
+		if n < w.Weight {
+			return w.Behaviour
+		}
+
+		n -= w.Weight
+	}
+
+	return m[len(m)-1].Behaviour
+}
+
+// result - result of one request.
+type result struct {
+	behaviour string
+	outcome   string
+	latency   time.Duration
+}
+
+// Run - run load test and returns report.
+func Run(ctx context.Context, opts Opts) (Report, error) {
+	if opts.Clients <= 0 || opts.Timeout <= 0 || opts.RampUp < 0 || (opts.Duration <= 0 && opts.Requests <= 0) ||
+		opts.Mix.total() == 0 {
+		return Report{}, ErrIncorrectOpts
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []result
+		started atomic.Int64
+		start   = time.Now()
+	)
+
+	for i := range opts.Clients {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			delay := time.Duration(0)
+			if opts.Clients > 1 {
+				delay = opts.RampUp * time.Duration(i) / time.Duration(opts.Clients-1)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			for ctx.Err() == nil {
+				if opts.Requests > 0 && started.Add(1) > int64(opts.Requests) {
+					return
+				}
+
+				r := runRequest(ctx, opts)
+
+				// request interrupted by test end isn't counted.
+				if ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				results = append(results, r)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return newReport(results, time.Since(start)), nil
+}
+
+func runRequest(ctx context.Context, opts Opts) result {
+	r := result{behaviour: opts.Mix.pick()}
+	start := time.Now()
+
+	dialer := net.Dialer{Timeout: opts.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", opts.Address)
+	if err != nil {
+		r.outcome = outcomeDialPrefix + errorOutcome(err)
+		r.latency = time.Since(start)
+
+		return r
+	}
+
+	defer conn.Close()
+
+	// connection is closed on test end to interrupt long requests.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	_ = conn.SetDeadline(start.Add(opts.Timeout))
+
+	r.outcome = behaviours[r.behaviour](conn, opts.Service)
+	r.latency = time.Since(start)
+
+	return r
+}
+
+// Report - load test report, times are in milliseconds.
+type Report struct {
+	Requests   int                        `json:"requests"`
+	Duration   float64                    `json:"duration_ms"`
+	Throughput float64                    `json:"throughput"` // requests per second.
+	Behaviours map[string]BehaviourReport `json:"behaviours"`
+}
+
+// BehaviourReport - report of one behaviour.
+// Resources - number of requests which received resource, all for honest clients and zero for malicious ones is fine.
+// Outcomes - number of requests by server error message or local error.
+type BehaviourReport struct {
+	Requests  int            `json:"requests"`
+	Resources int            `json:"resources"`
+	Latency   Latency        `json:"latency"`
+	Outcomes  map[string]int `json:"outcomes"`
+}
+
+// Latency - request latency percentiles in milliseconds.
+type Latency struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+func newReport(results []result, elapsed time.Duration) Report {
+	report := Report{
+		Requests:   len(results),
+		Duration:   milliseconds(elapsed),
+		Behaviours: make(map[string]BehaviourReport),
+	}
+
+	if elapsed > 0 {
+		report.Throughput = float64(len(results)) / elapsed.Seconds()
+	}
+
+	latencies := make(map[string][]time.Duration)
+
+	for _, r := range results {
+		b := report.Behaviours[r.behaviour]
+		if b.Outcomes == nil {
+			b.Outcomes = make(map[string]int)
+		}
+
+		b.Requests++
+
+		if r.outcome == OutcomeResource {
+			b.Resources++
+		} else {
+			b.Outcomes[r.outcome]++
+		}
+
+		report.Behaviours[r.behaviour] = b
+		latencies[r.behaviour] = append(latencies[r.behaviour], r.latency)
+	}
+
+	for behaviour, l := range latencies {
+		slices.Sort(l)
+
+		b := report.Behaviours[behaviour]
+		b.Latency = Latency{
+			P50: milliseconds(percentile(l, 0.5)),  //nolint:gomnd // percentile.
+			P90: milliseconds(percentile(l, 0.9)),  //nolint:gomnd // percentile.
+			P99: milliseconds(percentile(l, 0.99)), //nolint:gomnd // percentile.
+			Max: milliseconds(l[len(l)-1]),
+		}
+		report.Behaviours[behaviour] = b
+	}
+
+	return report
+}
+
+// percentile - returns nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(rank, 0)]
+}
+
+// sortedKeys - returns map keys in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,218 @@
+package loadtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+)
+
+func Test_ParseMix(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		mix, err := ParseMix("honest=90, garbage=10")
+		require.NoError(t, err)
+		require.Equal(t, Mix{{BehaviourHonest, 90}, {BehaviourGarbage, 10}}, mix)
+		require.Equal(t, "honest=90,garbage=10", mix.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for s, expected := range map[string]error{
+			"":                 ErrIncorrectMix,
+			"honest":           ErrIncorrectMix,
+			"honest=x":         ErrIncorrectMix,
+			"honest=-1":        ErrIncorrectMix,
+			"honest=0":         ErrIncorrectMix,
+			"honest=1,ddos=1":  ErrUnknownBehaviour,
+			"honest=1;garbage": ErrIncorrectMix,
+		} {
+			_, err := ParseMix(s)
+			require.ErrorIs(t, err, expected, s)
+		}
+	})
+}
+
+func Test_newReport(t *testing.T) {
+	t.Run("latency percentiles and outcomes", func(t *testing.T) {
+		var results []result
+		for i := 1; i <= 100; i++ {
+			results = append(results, result{behaviour: BehaviourHonest, outcome: OutcomeResource,
+				latency: time.Duration(i) * time.Millisecond})
+		}
+
+		results = append(results,
+			result{behaviour: BehaviourGarbage, outcome: "incorrect message format", latency: time.Millisecond},
+			result{behaviour: BehaviourGarbage, outcome: OutcomeConnectionClosed, latency: 3 * time.Millisecond},
+		)
+
+		report := newReport(results, 2*time.Second)
+
+		require.Equal(t, 102, report.Requests)
+		require.InDelta(t, 51, report.Throughput, 0.001)
+		require.Equal(t, BehaviourReport{
+			Requests:  100,
+			Resources: 100,
+			Latency:   Latency{P50: 50, P90: 90, P99: 99, Max: 100},
+			Outcomes:  map[string]int{},
+		}, report.Behaviours[BehaviourHonest])
+		require.Equal(t, BehaviourReport{
+			Requests: 2,
+			Latency:  Latency{P50: 1, P90: 3, P99: 3, Max: 3},
+			Outcomes: map[string]int{"incorrect message format": 1, OutcomeConnectionClosed: 1},
+		}, report.Behaviours[BehaviourGarbage])
+	})
+}
+
+// stubService - honest client stub, always receives resource.
+type stubService struct{}
+
+func (stubService) RequestResource(_ string, _ io.ReadWriter) (string, error) {
+	return "resource", nil
+}
+
+// listenStub - starts server stub which responds with a puzzle to puzzle requests and with an error to others.
+func listenStub(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				raw, err := bufio.NewReader(conn).ReadString(message.DelimiterMessage)
+				if err != nil {
+					return
+				}
+
+				msg := message.Message{Command: message.CommandError, Payload: "stub error"}
+				if raw == "1:hashcash\n" {
+					msg = message.Message{Command: message.CommandResponsePuzzle, Payload: "puzzle"}
+				}
+
+				_, _ = conn.Write(msg.Bytes())
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func Test_Run(t *testing.T) {
+	t.Run("requests limit", func(t *testing.T) {
+		report, err := Run(context.Background(), Opts{
+			Address:  listenStub(t),
+			Service:  stubService{},
+			Clients:  4,
+			Requests: 40,
+			Timeout:  time.Second,
+			Mix:      Mix{{BehaviourHonest, 1}, {BehaviourBadSolution, 1}, {BehaviourNeverSolve, 1}, {BehaviourGarbage, 1}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 40, report.Requests)
+
+		for behaviour, b := range report.Behaviours {
+			switch behaviour {
+			case BehaviourHonest:
+				require.Equal(t, b.Requests, b.Resources)
+			case BehaviourBadSolution:
+				require.Equal(t, map[string]int{OutcomeConnectionClosed: b.Requests}, b.Outcomes)
+			case BehaviourNeverSolve:
+				require.Equal(t, map[string]int{OutcomeConnectionClosed: b.Requests}, b.Outcomes)
+			case BehaviourGarbage:
+				require.Equal(t, map[string]int{"stub error": b.Requests}, b.Outcomes)
+			}
+		}
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		address := listener.Addr().String()
+		listener.Close()
+
+		report, err := Run(context.Background(), Opts{
+			Address:  address,
+			Clients:  1,
+			Requests: 1,
+			Timeout:  time.Second,
+			Mix:      Mix{{BehaviourGarbage, 1}},
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Behaviours[BehaviourGarbage].Outcomes, 1)
+
+		for outcome := range report.Behaviours[BehaviourGarbage].Outcomes {
+			require.Contains(t, outcome, outcomeDialPrefix)
+		}
+	})
+
+	t.Run("duration limit interrupts slow requests", func(t *testing.T) {
+		start := time.Now()
+
+		report, err := Run(context.Background(), Opts{
+			Address:  listenStub(t),
+			Clients:  2,
+			Duration: 100 * time.Millisecond,
+			Timeout:  time.Minute,
+			Mix:      Mix{{BehaviourSlowloris, 1}},
+		})
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), time.Second)
+		require.Zero(t, report.Requests)
+	})
+
+	t.Run("incorrect options", func(t *testing.T) {
+		_, err := Run(context.Background(), Opts{Clients: 1, Timeout: time.Second, Mix: Mix{{BehaviourHonest, 1}}})
+		require.ErrorIs(t, err, ErrIncorrectOpts)
+	})
+}
+
+func Test_RunCommand(t *testing.T) {
+	t.Run("json report ok", func(t *testing.T) {
+		t.Setenv("CLIENT_SERVER_ADDRESS", listenStub(t))
+
+		var out bytes.Buffer
+
+		err := RunCommand([]string{"-json", "-clients", "2", "-requests", "4", "-duration", "0"}, &out,
+			func(*config.Config) Service { return stubService{} })
+		require.NoError(t, err)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		require.Equal(t, 4, report.Behaviours[BehaviourHonest].Resources)
+	})
+
+	t.Run("text report ok", func(t *testing.T) {
+		t.Setenv("CLIENT_SERVER_ADDRESS", listenStub(t))
+
+		var out bytes.Buffer
+
+		err := RunCommand([]string{"-mix", "honest=1,garbage=1", "-requests", "4", "-duration", "0"}, &out,
+			func(*config.Config) Service { return stubService{} })
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "requests: 4")
+	})
+
+	t.Run("unknown behaviour", func(t *testing.T) {
+		err := RunCommand([]string{"-mix", "ddos=1"}, io.Discard, nil)
+		require.ErrorIs(t, err, ErrUnknownBehaviour)
+	})
+}
@@ -0,0 +1,109 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/config"
+)
+
+// DefaultMix - default behaviours mix, honest clients only.
+const DefaultMix = BehaviourHonest + "=1"
+
+// RunCommand - run load test subcommand with command line args and print report to w.
+// Server address is taken from config, newService creates service for honest clients.
+func RunCommand(args []string, w io.Writer, newService func(c *config.Config) Service) error {
+	const (
+		defaultClients  = 10
+		defaultDuration = 10 * time.Second
+		defaultTimeout  = 30 * time.Second
+	)
+
+	var (
+		fs      = flag.NewFlagSet(Command, flag.ContinueOnError)
+		path    = fs.String("config", "", "config file path")
+		jsonOut = fs.Bool("json", false, "print report as json")
+		mix     = fs.String("mix", DefaultMix, "behaviours weights: "+
+			"honest, bad-solution, never-solve, slowloris, garbage, e.g. honest=90,garbage=10")
+		opts Opts
+	)
+
+	fs.SetOutput(w)
+	fs.IntVar(&opts.Clients, "clients", defaultClients, "number of concurrent virtual clients")
+	fs.DurationVar(&opts.Duration, "duration", defaultDuration, "test duration, 0 - until requests are sent")
+	fs.IntVar(&opts.Requests, "requests", 0, "total number of requests, 0 - until duration passes")
+	fs.DurationVar(&opts.RampUp, "ramp-up", 0, "duration to start all clients evenly")
+	fs.DurationVar(&opts.Timeout, "timeout", defaultTimeout, "max duration of one request")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+
+	var err error
+
+	if opts.Mix, err = ParseMix(*mix); err != nil {
+		return err
+	}
+
+	c, err := config.Load(*path)
+	if err != nil {
+		return err //nolint:wrapcheck // config error.
+	}
+
+	opts.Address = c.Client.ServerAddress
+	opts.Service = newService(c)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	report, err := Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report) //nolint:wrapcheck // encode error.
+	}
+
+	return printReport(w, report)
+}
+
+func printReport(w io.Writer, report Report) error {
+	fmt.Fprintf(w, "requests: %d\nduration: %s\nthroughput: %.1f/s\n\n",
+		report.Requests, duration(report.Duration), report.Throughput)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:gomnd // padding.
+	fmt.Fprintln(tw, "behaviour\trequests\tresources\tp50\tp90\tp99\tmax")
+
+	for _, behaviour := range sortedKeys(report.Behaviours) {
+		b := report.Behaviours[behaviour]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", behaviour, b.Requests, b.Resources,
+			duration(b.Latency.P50), duration(b.Latency.P90), duration(b.Latency.P99), duration(b.Latency.Max))
+	}
+
+	fmt.Fprintln(tw, "\nbehaviour\toutcome\tcount")
+
+	for _, behaviour := range sortedKeys(report.Behaviours) {
+		outcomes := report.Behaviours[behaviour].Outcomes
+		for _, outcome := range sortedKeys(outcomes) {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", behaviour, outcome, outcomes[outcome])
+		}
+	}
+
+	return tw.Flush() //nolint:wrapcheck // print error.
+}
+
+// duration - format milliseconds as duration rounded to microseconds.
+func duration(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Microsecond).String()
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	connStateIdle int32 = iota
+	connStateInFlight
+	connStateClosing
+)
+
+func newTrackedConn(conn net.Conn) *trackedConn {
+	return &trackedConn{
+		Conn:        conn,
+		connectedAt: time.Now(),
+	}
+}
+
+// trackedConn - connection which tracks whether session is idle or in-flight.
+// Session is idle until client sends the first bytes.
+type trackedConn struct {
+	net.Conn
+	state       atomic.Int32
+	connectedAt time.Time
+
+	// deadline - session deadline, readTimeout - limit of a single read, 0 - only session deadline.
+	deadline    time.Time
+	readTimeout time.Duration
+}
+
+// setDeadline - set session deadline and limit of a single read within it.
+func (c *trackedConn) setDeadline(deadline time.Time, readTimeout time.Duration) error {
+	c.deadline, c.readTimeout = deadline, readTimeout
+
+	return c.SetReadDeadline(deadline) //nolint:wrapcheck // conn error.
+}
+
+// Read - read from connection and mark session as in-flight on the first data.
+// Every read waits not longer than read timeout and session deadline.
+func (c *trackedConn) Read(b []byte) (int, error) {
+	if c.readTimeout > 0 {
+		deadline := time.Now().Add(c.readTimeout)
+		if !c.deadline.IsZero() && c.deadline.Before(deadline) {
+			deadline = c.deadline
+		}
+
+		if err := c.SetReadDeadline(deadline); err != nil {
+			return 0, err //nolint:wrapcheck // conn error.
+		}
+	}
+
+	n, err := c.Conn.Read(b)
+	if n > 0 && !c.state.CompareAndSwap(connStateIdle, connStateInFlight) && c.state.Load() == connStateClosing {
+		return 0, net.ErrClosed
+	}
+
+	return n, err
+}
+
+// markClosing - mark idle session as closing, returns false if session is already in-flight.
+func (c *trackedConn) markClosing() bool {
+	return c.state.CompareAndSwap(connStateIdle, connStateClosing)
+}
+
+// isClosing - check if session was notified about shutdown.
+func (c *trackedConn) isClosing() bool {
+	return c.state.Load() == connStateClosing
+}
+
+// info - returns connection description.
+func (c *trackedConn) info() ConnectionInfo {
+	var state string
+
+	switch c.state.Load() {
+	case connStateInFlight:
+		state = "in-flight"
+	case connStateClosing:
+		state = "closing"
+	default:
+		state = "idle"
+	}
+
+	return ConnectionInfo{
+		ClientID:    c.RemoteAddr().String(),
+		State:       state,
+		ConnectedAt: c.connectedAt,
+	}
+}
+
+// ConnectionInfo - active connection description.
+type ConnectionInfo struct {
+	ClientID    string
+	State       string
+	ConnectedAt time.Time
+}
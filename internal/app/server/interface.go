@@ -10,6 +10,11 @@ type Config interface {
 	Address() string
 	ShutdownTimeout() time.Duration
 	ConnectionTimeout() time.Duration
+	ReadTimeout() time.Duration
+	Workers() int
+	QueueSize() int
+	QueueTimeout() time.Duration
+	BusyRetryAfter() time.Duration
 }
 
 // Logger - logger interface.
@@ -20,7 +25,14 @@ type Logger interface {
 	Debug(msg string, args ...any)
 }
 
+// Banlist - banned clients checker.
+type Banlist interface {
+	IsBanned(addr string) bool
+}
+
 // Service - server service to handle client messages.
 type Service interface {
 	HandleMessages(clientID string, rw io.ReadWriter)
+	HandleShutdown(clientID string, w io.Writer)
+	HandleBusy(clientID string, w io.Writer, retryAfter time.Duration)
 }
@@ -22,6 +22,17 @@ func Listen(ctx context.Context, opts Opts) (*Server, error) {
 		config:   opts.Config,
 		logger:   opts.Logger,
 		service:  opts.Service,
+		banlist:  opts.Banlist,
+		conns:    make(map[*trackedConn]struct{}),
+	}
+
+	if workers := opts.Config.Workers(); workers > 0 {
+		server.queue = make(chan *trackedConn, opts.Config.QueueSize())
+		server.waiters = make(chan struct{}, workers+opts.Config.QueueSize())
+
+		for range workers {
+			go server.runWorker()
+		}
 	}
 
 	server.shutdownWg.Add(1)
@@ -31,10 +42,12 @@ func Listen(ctx context.Context, opts Opts) (*Server, error) {
 }
 
 // Opts - options to run server.
+// Banlist - optional, connections from banned addresses are closed at once.
 type Opts struct {
 	Config  Config
 	Logger  Logger
 	Service Service
+	Banlist Banlist
 }
 
 // Sever - tcp server.
@@ -43,34 +56,137 @@ type Server struct {
 	config   Config
 	logger   Logger
 	service  Service
+	banlist  Banlist
 
 	shutdownWg    sync.WaitGroup
 	isShutingDown atomic.Bool
+
+	connsWg sync.WaitGroup
+	connsMu sync.Mutex
+	conns   map[*trackedConn]struct{}
+
+	// queue - connections waiting for free worker, nil if worker pool is disabled.
+	queue chan *trackedConn
+	// waiters - bounds number of connections waiting for free place in full queue,
+	// so accept loop never blocks on full queue.
+	waiters chan struct{}
+	// waitersWg - connections waiting for free place in queue, queue is closed after them.
+	waitersWg sync.WaitGroup
+}
+
+// Addr - returns listener address, e.g. to find out port picked for ":0" address.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// forceCloseWait - how long shutdown waits for handlers of force-closed sessions to return.
+const forceCloseWait = 100 * time.Millisecond
+
+// ShutdownReport - result of server shutdown.
+// Drained - sessions finished gracefully, including idle sessions notified about shutdown.
+// Killed - in-flight sessions force-closed after shutdown timeout.
+// Leftover - sessions which handlers didn't return after force-close, e.g. busy with verification,
+// they're abandoned and finish in background.
+type ShutdownReport struct {
+	Drained  int
+	Killed   int
+	Leftover int
 }
 
 // Shutdown - shutdown server gracefully.
-func (s *Server) Shutdown() {
+// Idle sessions are notified and closed at once, in-flight sessions have shutdown timeout to finish,
+// remaining sessions are force-closed, shutdown doesn't wait for their handlers longer than forceCloseWait.
+func (s *Server) Shutdown() ShutdownReport {
 	const operationName = "server.Shutdown"
 
+	var report ShutdownReport
+
 	s.isShutingDown.Store(true)
 	s.listener.Close()
+	s.shutdownWg.Wait()
+
+	// No connections are tracked after accept loop is finished, so report is built from this set.
+	conns := s.activeConns()
+	for _, conn := range conns {
+		if conn.markClosing() {
+			s.service.HandleShutdown(conn.RemoteAddr().String(), conn)
+			conn.Close()
+		}
+	}
 
 	done := make(chan struct{})
 	go func() {
-		s.shutdownWg.Wait()
+		s.connsWg.Wait()
 		close(done)
 	}()
 
 	select {
 	case <-done:
 		s.logger.Debug("shutdown server gracefully", "operationName", operationName)
-
-		return
 	case <-time.After(s.config.ShutdownTimeout()):
-		s.logger.Debug("shutdown server by timeout", "operationName", operationName)
+		for _, conn := range conns {
+			if !s.isTracked(conn) {
+				continue
+			}
 
-		return
+			conn.Close()
+
+			if !conn.isClosing() {
+				report.Killed++
+			}
+		}
+
+		select {
+		case <-done:
+			s.logger.Debug("shutdown server by timeout", "operationName", operationName)
+		case <-time.After(forceCloseWait):
+			for _, conn := range conns {
+				if s.isTracked(conn) {
+					report.Leftover++
+				}
+			}
+
+			s.logger.Warn("shutdown server by timeout, sessions are left", "operationName", operationName,
+				"leftover", report.Leftover)
+		}
+	}
+
+	report.Drained = len(conns) - report.Killed
+
+	return report
+}
+
+// Connections - returns active connections.
+func (s *Server) Connections() []ConnectionInfo {
+	conns := s.activeConns()
+
+	infos := make([]ConnectionInfo, 0, len(conns))
+	for _, conn := range conns {
+		infos = append(infos, conn.info())
 	}
+
+	return infos
+}
+
+// Kick - close all connections from ip, returns number of closed connections.
+func (s *Server) Kick(ip string) int {
+	const operationName = "server.Kick"
+
+	var kicked int
+
+	for _, conn := range s.activeConns() {
+		host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil || host != ip {
+			continue
+		}
+
+		conn.Close()
+		kicked++
+	}
+
+	s.logger.Info("clients kicked", "operationName", operationName, "ip", ip, "kicked", kicked)
+
+	return kicked
 }
 
 func (s *Server) acceptConnections(_ context.Context) {
@@ -78,6 +194,13 @@ func (s *Server) acceptConnections(_ context.Context) {
 
 	defer s.shutdownWg.Done()
 
+	if s.queue != nil {
+		defer func() {
+			s.waitersWg.Wait()
+			close(s.queue)
+		}()
+	}
+
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
@@ -92,27 +215,147 @@ func (s *Server) acceptConnections(_ context.Context) {
 			continue
 		}
 
-		go s.handleConnection(conn)
+		if s.banlist != nil && s.banlist.IsBanned(conn.RemoteAddr().String()) {
+			s.logger.Debug("banned client rejected", "operationName", operationName,
+				"clientID", conn.RemoteAddr().String())
+			conn.Close()
+
+			continue
+		}
+
+		if s.queue == nil {
+			go s.handleConnection(s.trackConn(conn))
+
+			continue
+		}
+
+		s.enqueueConnection(conn)
 	}
 }
 
-func (s *Server) handleConnection(conn net.Conn) {
-	const operationName = "server.handleConnection"
+// enqueueConnection - pass connection to worker pool without blocking accept loop.
+// If queue is full, up to workers + queue size connections wait for free place in queue for queue timeout
+// in background, others are rejected with busy error at once.
+func (s *Server) enqueueConnection(conn net.Conn) {
+	tracked := s.trackConn(conn)
 
-	defer conn.Close()
+	select {
+	case s.queue <- tracked:
+		return
+	default:
+	}
 
-	err := conn.SetReadDeadline(time.Now().Add(s.config.ConnectionTimeout()))
-	if err != nil {
-		s.logger.Error(err.Error(), "operationName", operationName)
+	select {
+	case s.waiters <- struct{}{}:
+	default:
+		s.rejectConnection(tracked)
 
 		return
 	}
 
+	s.waitersWg.Add(1)
+
+	go func() {
+		defer func() {
+			<-s.waiters
+			s.waitersWg.Done()
+		}()
+
+		timer := time.NewTimer(s.config.QueueTimeout())
+		defer timer.Stop()
+
+		select {
+		case s.queue <- tracked:
+		case <-timer.C:
+			s.rejectConnection(tracked)
+		}
+	}()
+}
+
+// rejectConnection - notify client that server is busy and close connection.
+func (s *Server) rejectConnection(conn *trackedConn) {
+	const operationName = "server.rejectConnection"
+
+	s.logger.Warn("queue is full, connection rejected", "operationName", operationName,
+		"clientID", conn.RemoteAddr().String())
+
+	if conn.markClosing() {
+		s.service.HandleBusy(conn.RemoteAddr().String(), conn, s.config.BusyRetryAfter())
+	}
+
+	conn.Close()
+	s.untrackConn(conn)
+}
+
+func (s *Server) runWorker() {
+	for conn := range s.queue {
+		s.handleConnection(conn)
+	}
+}
+
+func (s *Server) handleConnection(conn *trackedConn) {
+	const operationName = "server.handleConnection"
+
+	defer s.untrackConn(conn)
+	defer conn.Close()
+
 	if s.isShutingDown.Load() {
 		s.logger.Error("server closed", "operationName", operationName)
 
+		if conn.markClosing() {
+			s.service.HandleShutdown(conn.RemoteAddr().String(), conn)
+		}
+
+		return
+	}
+
+	err := conn.setDeadline(time.Now().Add(s.config.ConnectionTimeout()), s.config.ReadTimeout())
+	if err != nil {
+		s.logger.Error(err.Error(), "operationName", operationName)
+
 		return
 	}
 
 	s.service.HandleMessages(conn.RemoteAddr().String(), conn)
 }
+
+func (s *Server) trackConn(conn net.Conn) *trackedConn {
+	tracked := newTrackedConn(conn)
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	s.conns[tracked] = struct{}{}
+	s.connsWg.Add(1)
+
+	return tracked
+}
+
+func (s *Server) untrackConn(conn *trackedConn) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	delete(s.conns, conn)
+	s.connsWg.Done()
+}
+
+func (s *Server) isTracked(conn *trackedConn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	_, ok := s.conns[conn]
+
+	return ok
+}
+
+func (s *Server) activeConns() []*trackedConn {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	conns := make([]*trackedConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+
+	return conns
+}
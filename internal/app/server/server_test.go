@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockConfig struct {
+	shutdownTimeout time.Duration
+	readTimeout     time.Duration
+	workers         int
+	queueSize       int
+}
+
+func (c *mockConfig) Address() string                  { return "127.0.0.1:0" }
+func (c *mockConfig) ShutdownTimeout() time.Duration   { return c.shutdownTimeout }
+func (c *mockConfig) ConnectionTimeout() time.Duration { return time.Minute }
+func (c *mockConfig) ReadTimeout() time.Duration       { return c.readTimeout }
+func (c *mockConfig) Workers() int                     { return c.workers }
+func (c *mockConfig) QueueSize() int                   { return c.queueSize }
+func (c *mockConfig) QueueTimeout() time.Duration      { return 50 * time.Millisecond }
+func (c *mockConfig) BusyRetryAfter() time.Duration    { return 500 * time.Millisecond }
+
+type mockLogger struct{}
+
+func (l *mockLogger) Info(_ string, _ ...any)  {}
+func (l *mockLogger) Warn(_ string, _ ...any)  {}
+func (l *mockLogger) Error(_ string, _ ...any) {}
+func (l *mockLogger) Debug(_ string, _ ...any) {}
+
+// mockService - reads one line and than waits for finish signal or connection close.
+// Session which sends "stuck" line ignores connection close, like handler busy with verification.
+type mockService struct {
+	finish chan struct{}
+}
+
+func (s *mockService) HandleMessages(_ string, rw io.ReadWriter) {
+	line, err := bufio.NewReader(rw).ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	if line == "stuck\n" {
+		<-s.finish
+
+		return
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_, _ = rw.Read(make([]byte, 1))
+		close(closed)
+	}()
+
+	select {
+	case <-s.finish:
+	case <-closed:
+	}
+}
+
+func (s *mockService) HandleShutdown(_ string, w io.Writer) {
+	_, _ = w.Write([]byte("0:server shutting down\n"))
+}
+
+func (s *mockService) HandleBusy(_ string, w io.Writer, retryAfter time.Duration) {
+	_, _ = fmt.Fprintf(w, "0:server busy, retry later; retry_after_ms=%d\n", retryAfter.Milliseconds())
+}
+
+func startServer(t *testing.T, config *mockConfig) (*Server, *mockService) {
+	t.Helper()
+
+	service := &mockService{finish: make(chan struct{})}
+	srv, err := Listen(context.Background(), Opts{
+		Config:  config,
+		Logger:  &mockLogger{},
+		Service: service,
+	})
+	require.NoError(t, err)
+
+	return srv, service
+}
+
+func dial(t *testing.T, srv *Server, payload string) net.Conn {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", srv.listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	if payload != "" {
+		_, err = conn.Write([]byte(payload))
+		require.NoError(t, err)
+	}
+
+	return conn
+}
+
+func Test_Shutdown(t *testing.T) {
+	waitConns := func(t *testing.T, srv *Server, n int) {
+		t.Helper()
+
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == n }, time.Second, 5*time.Millisecond)
+	}
+
+	t.Run("idle session notified, in-flight session killed", func(t *testing.T) {
+		srv, _ := startServer(t, &mockConfig{shutdownTimeout: 100 * time.Millisecond})
+
+		idle := dial(t, srv, "")
+		dial(t, srv, "1:\n")
+		waitConns(t, srv, 2)
+		time.Sleep(50 * time.Millisecond)
+
+		report := srv.Shutdown()
+		require.Equal(t, ShutdownReport{Drained: 1, Killed: 1}, report)
+
+		msg, err := bufio.NewReader(idle).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "0:server shutting down\n", msg)
+	})
+
+	t.Run("stuck session is left after force-close", func(t *testing.T) {
+		srv, service := startServer(t, &mockConfig{shutdownTimeout: 100 * time.Millisecond})
+		defer close(service.finish)
+
+		dial(t, srv, "")
+		dial(t, srv, "stuck\n")
+		waitConns(t, srv, 2)
+		time.Sleep(50 * time.Millisecond)
+
+		start := time.Now()
+		report := srv.Shutdown()
+		require.Less(t, time.Since(start), time.Second)
+		require.Equal(t, ShutdownReport{Drained: 1, Killed: 1, Leftover: 1}, report)
+	})
+
+	t.Run("in-flight session drained within timeout", func(t *testing.T) {
+		srv, service := startServer(t, &mockConfig{shutdownTimeout: time.Second})
+
+		dial(t, srv, "1:\n")
+		waitConns(t, srv, 1)
+		time.Sleep(50 * time.Millisecond)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			close(service.finish)
+		}()
+
+		report := srv.Shutdown()
+		require.Equal(t, ShutdownReport{Drained: 1, Killed: 0}, report)
+	})
+}
+
+func Test_WorkerPool(t *testing.T) {
+	t.Run("connection rejected when queue is full", func(t *testing.T) {
+		srv, service := startServer(t, &mockConfig{shutdownTimeout: time.Second, workers: 1})
+
+		dial(t, srv, "1:\n")
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 1 }, time.Second, 5*time.Millisecond)
+
+		rejected := dial(t, srv, "1:\n")
+		msg, err := bufio.NewReader(rejected).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "0:server busy, retry later; retry_after_ms=500\n", msg)
+
+		close(service.finish)
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 0 }, time.Second, 5*time.Millisecond)
+
+		accepted := dial(t, srv, "")
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 1 }, time.Second, 5*time.Millisecond)
+
+		report := srv.Shutdown()
+		require.Equal(t, ShutdownReport{Drained: 1, Killed: 0}, report)
+
+		msg, err = bufio.NewReader(accepted).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "0:server shutting down\n", msg)
+	})
+
+	t.Run("accept isn't blocked when waiting connections are over", func(t *testing.T) {
+		srv, _ := startServer(t, &mockConfig{shutdownTimeout: time.Second, workers: 1, queueSize: 1})
+
+		dial(t, srv, "1:\n")
+		dial(t, srv, "1:\n")
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 2 }, time.Second, 5*time.Millisecond)
+
+		waiting := []net.Conn{dial(t, srv, "1:\n"), dial(t, srv, "1:\n")}
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 4 }, time.Second, 5*time.Millisecond)
+
+		rejected := dial(t, srv, "1:\n")
+		msg, err := bufio.NewReader(rejected).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "0:server busy, retry later; retry_after_ms=500\n", msg)
+
+		for _, conn := range waiting {
+			msg, err = bufio.NewReader(conn).ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, "0:server busy, retry later; retry_after_ms=500\n", msg)
+		}
+	})
+
+	t.Run("idle connection releases worker after read timeout", func(t *testing.T) {
+		srv, _ := startServer(t, &mockConfig{shutdownTimeout: time.Second, workers: 1, readTimeout: 50 * time.Millisecond})
+
+		idle := dial(t, srv, "")
+
+		_, err := bufio.NewReader(idle).ReadString('\n')
+		require.ErrorIs(t, err, io.EOF)
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 0 }, time.Second, 5*time.Millisecond)
+
+		dial(t, srv, "1:\n")
+		require.Eventually(t, func() bool { return len(srv.activeConns()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+}
@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+// config - harness config, implements config interfaces of server, client and their services.
+type config struct {
+	opts    Opts
+	address string
+}
+
+func (c *config) Address() string {
+	return c.opts.Address
+}
+
+func (c *config) ShutdownTimeout() time.Duration {
+	return c.opts.ShutdownTimeout
+}
+
+func (c *config) ConnectionTimeout() time.Duration {
+	return c.opts.ConnectionTimeout
+}
+
+func (c *config) ReadTimeout() time.Duration {
+	return c.opts.ReadTimeout
+}
+
+func (c *config) Workers() int {
+	return c.opts.Workers
+}
+
+func (c *config) QueueSize() int {
+	return c.opts.QueueSize
+}
+
+func (c *config) QueueTimeout() time.Duration {
+	return c.opts.QueueTimeout
+}
+
+func (c *config) BusyRetryAfter() time.Duration {
+	return c.opts.BusyRetryAfter
+}
+
+func (c *config) PuzzleTTL() time.Duration {
+	return c.opts.PuzzleTTL
+}
+
+func (c *config) PuzzleClockSkew() time.Duration {
+	return c.opts.ClockSkew
+}
+
+func (c *config) PuzzleZeroBits() int {
+	return c.opts.ZeroBits
+}
+
+func (c *config) PuzzleAlgorithm() hashcash.Algorithm {
+	return hashcash.SHA256{}
+}
+
+func (c *config) PuzzleVerifyConcurrency() int {
+	return 1
+}
+
+func (c *config) MaxPuzzlesPerConnection() int {
+	return c.opts.MaxPuzzlesPerConnection
+}
+
+func (c *config) PuzzleComputeMaxAttempts() int {
+	return c.opts.ComputeMaxAttempts
+}
+
+func (c *config) ServerAddress() string {
+	return c.address
+}
+
+func (c *config) MaxRetries() int {
+	return c.opts.MaxRetries
+}
+
+func (c *config) RetryBaseDelay() time.Duration {
+	return c.opts.RetryBaseDelay
+}
+
+func (c *config) RetryMaxDelay() time.Duration {
+	return c.opts.RetryMaxDelay
+}
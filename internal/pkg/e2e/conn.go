@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+)
+
+// receiveTimeout - limit to wait for server message, so broken test fails instead of hanging.
+const receiveTimeout = 5 * time.Second
+
+func newConn(t testing.TB, conn net.Conn, computeMaxAttempts int) *Conn {
+	return &Conn{
+		Conn:               conn,
+		t:                  t,
+		r:                  bufio.NewReader(conn),
+		computeMaxAttempts: computeMaxAttempts,
+	}
+}
+
+// Conn - raw client connection to drive protocol step by step.
+type Conn struct {
+	net.Conn
+	t                  testing.TB
+	r                  *bufio.Reader
+	computeMaxAttempts int
+}
+
+// Send - send message.
+func (c *Conn) Send(msg message.Message) {
+	c.t.Helper()
+
+	_, err := c.Write(msg.Bytes())
+	require.NoError(c.t, err)
+}
+
+// SendRaw - send raw bytes, e.g. malformed message.
+func (c *Conn) SendRaw(raw string) {
+	c.t.Helper()
+
+	_, err := c.Write([]byte(raw))
+	require.NoError(c.t, err)
+}
+
+// Receive - receive next server message, returns error if connection is closed.
+func (c *Conn) Receive() (message.Message, error) {
+	if err := c.SetReadDeadline(time.Now().Add(receiveTimeout)); err != nil {
+		return message.Message{}, err //nolint:wrapcheck // conn error.
+	}
+
+	raw, err := c.r.ReadString(message.DelimiterMessage)
+	if err != nil {
+		return message.Message{}, err //nolint:wrapcheck // conn error.
+	}
+
+	return message.ParseMessage(raw) //nolint:wrapcheck // message error.
+}
+
+// Exchange - send message and receive response.
+func (c *Conn) Exchange(msg message.Message) message.Message {
+	c.t.Helper()
+
+	c.Send(msg)
+
+	res, err := c.Receive()
+	require.NoError(c.t, err)
+
+	return res
+}
+
+// RequestPuzzle - request hashcash puzzle, returns puzzle envelope.
+func (c *Conn) RequestPuzzle() string {
+	c.t.Helper()
+
+	res := c.Exchange(message.Message{Command: message.CommandRequestPuzzle, Payload: puzzle.SchemeHashcash})
+	require.Equal(c.t, message.CommandResponsePuzzle, res.Command, res.Payload)
+
+	return res.Payload
+}
+
+// Solve - solve puzzle from envelope, returns solution envelope to request resource.
+func (c *Conn) Solve(envelope string) string {
+	c.t.Helper()
+
+	id, serialized, err := puzzle.Unwrap(envelope)
+	require.NoError(c.t, err)
+
+	p, err := puzzle.NewHashcashScheme(nil, nil).Parse(serialized)
+	require.NoError(c.t, err)
+	require.NoError(c.t, p.Solve(c.computeMaxAttempts, nil))
+
+	return puzzle.Wrap(id, p)
+}
+
+// RequestResource - send solution, returns server response.
+func (c *Conn) RequestResource(solution string) message.Message {
+	c.t.Helper()
+
+	return c.Exchange(message.Message{Command: message.CommandRequestResource, Payload: solution})
+}
+
+// RequireClosed - check that server closed connection.
+func (c *Conn) RequireClosed() {
+	c.t.Helper()
+
+	_, err := c.Receive()
+	require.Error(c.t, err)
+}
@@ -0,0 +1,258 @@
+// Package e2e - in-process harness to test server and client together over real TCP connections.
+// Server listens on an ephemeral port with low puzzle difficulty, time of puzzle issuing and expiry
+// is controlled by injected clock. Harness is stopped on test cleanup.
+package e2e
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/client"
+	"github.com/kamilkn/pow-tcp-server-client/internal/app/server"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/banlist"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/log"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/penalty"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/tcp"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
+)
+
+// DefaultResource - resource served if Opts.Resources is empty.
+const DefaultResource = "Wisdom is knowing how little we know."
+
+const (
+	defaultZeroBits                = 1
+	defaultPuzzleTTL               = time.Minute
+	defaultConnectionTimeout       = 5 * time.Second
+	defaultShutdownTimeout         = time.Second
+	defaultMaxPuzzlesPerConnection = 3
+	defaultComputeMaxAttempts      = 1 << 24
+	defaultRetryBaseDelay          = 10 * time.Millisecond
+	defaultRetryMaxDelay           = 100 * time.Millisecond
+	defaultViolationWindow         = time.Minute
+)
+
+// Opts - options to start harness, zero values are replaced with defaults for fast tests.
+// Address - listen address, "127.0.0.1:0" by default to pick ephemeral port.
+// Clock - optional, system clock is used by default, pass clock.Fake to control puzzle expiry.
+// ClockSkew - tolerated clock difference in puzzle dates, 0 - no tolerance.
+// Workers - worker pool size, 0 - goroutine per connection.
+// ViolationBanThreshold - number of violations to ban client ip, 0 - clients are never banned.
+// MaxRetries - retries of Connect, 0 - no retries.
+type Opts struct {
+	Address                 string
+	Clock                   clock.Clock
+	ZeroBits                int
+	PuzzleTTL               time.Duration
+	ClockSkew               time.Duration
+	ConnectionTimeout       time.Duration
+	ReadTimeout             time.Duration
+	ShutdownTimeout         time.Duration
+	Workers                 int
+	QueueSize               int
+	QueueTimeout            time.Duration
+	BusyRetryAfter          time.Duration
+	MaxPuzzlesPerConnection int
+	ViolationBanThreshold   int
+	ComputeMaxAttempts      int
+	MaxRetries              int
+	RetryBaseDelay          time.Duration
+	RetryMaxDelay           time.Duration
+	Resources               []string
+}
+
+func (o Opts) withDefaults() Opts {
+	if o.Address == "" {
+		o.Address = "127.0.0.1:0"
+	}
+
+	o.Clock = clock.OrReal(o.Clock)
+
+	defaults := []struct {
+		value *time.Duration
+		def   time.Duration
+	}{
+		{&o.PuzzleTTL, defaultPuzzleTTL},
+		{&o.ConnectionTimeout, defaultConnectionTimeout},
+		{&o.ShutdownTimeout, defaultShutdownTimeout},
+		{&o.RetryBaseDelay, defaultRetryBaseDelay},
+		{&o.RetryMaxDelay, defaultRetryMaxDelay},
+	}
+	for _, d := range defaults {
+		if *d.value == 0 {
+			*d.value = d.def
+		}
+	}
+
+	if o.ZeroBits == 0 {
+		o.ZeroBits = defaultZeroBits
+	}
+
+	if o.MaxPuzzlesPerConnection == 0 {
+		o.MaxPuzzlesPerConnection = defaultMaxPuzzlesPerConnection
+	}
+
+	if o.ComputeMaxAttempts == 0 {
+		o.ComputeMaxAttempts = defaultComputeMaxAttempts
+	}
+
+	if len(o.Resources) == 0 {
+		o.Resources = []string{DefaultResource}
+	}
+
+	return o
+}
+
+// Start - start server with service on harness config, server is shut down on test cleanup.
+func Start(t testing.TB, opts Opts) *Harness {
+	t.Helper()
+
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := log.New(log.Opts{Level: log.LevelError + 1})
+
+	h := &Harness{
+		Clock:   opts.Clock,
+		Banlist: banlist.New(),
+		t:       t,
+		cancel:  cancel,
+		logger:  logger,
+		config:  &config{opts: opts},
+	}
+
+	h.PuzzleCache = cache.New[string, int](ctx, cache.Opts{Logger: logger, Clock: opts.Clock})
+
+	resourceCache := cache.New[int, string](ctx, cache.Opts{Logger: logger})
+	for i, resource := range opts.Resources {
+		resourceCache.Add(i, resource)
+	}
+
+	h.Penalties = penalty.New(penalty.Opts{
+		BanThreshold: opts.ViolationBanThreshold,
+		Window:       defaultViolationWindow,
+		Banlist:      h.Banlist,
+		Clock:        opts.Clock,
+	})
+
+	h.Service = service.NewServer(&service.ServerOpts{
+		Logger:        logger,
+		Config:        h.config,
+		PuzzleCache:   h.PuzzleCache,
+		ResourceCache: resourceCache,
+		ErrorChecker:  tcp.NewConnErrorChecker(),
+		Schemes:       puzzle.NewRegistry(puzzle.NewHashcashScheme(h.config, nil)),
+		Penalties:     h.Penalties,
+		Clock:         opts.Clock,
+	})
+
+	var err error
+
+	h.Server, err = server.Listen(ctx, server.Opts{
+		Config:  h.config,
+		Logger:  logger,
+		Service: h.Service,
+		Banlist: h.Banlist,
+	})
+	if err != nil {
+		cancel()
+		require.NoError(t, err)
+	}
+
+	h.config.address = h.Server.Addr().String()
+
+	t.Cleanup(func() { h.Shutdown() })
+
+	return h
+}
+
+// Harness - running server with its dependencies and factories of clients connected to it.
+type Harness struct {
+	Server      *server.Server
+	Service     *service.Server
+	Clock       clock.Clock
+	PuzzleCache *cache.Cache[string, int]
+	Banlist     *banlist.Banlist
+	Penalties   *penalty.Box
+
+	t      testing.TB
+	cancel context.CancelFunc
+	logger *slog.Logger
+	config *config
+
+	shutdownOnce sync.Once
+	report       server.ShutdownReport
+}
+
+// Addr - returns server address.
+func (h *Harness) Addr() string {
+	return h.config.address
+}
+
+// Client - returns client-side service supporting hashcash scheme.
+func (h *Harness) Client(handshake bool) *service.Client {
+	return service.NewClient(service.ClientOpts{
+		Logger:    h.logger,
+		Config:    h.config,
+		Schemes:   puzzle.NewRegistry(puzzle.NewHashcashScheme(nil, nil)),
+		Handshake: handshake,
+	})
+}
+
+// Request - request resource by real client over new connection.
+func (h *Harness) Request(handshake bool) (string, error) {
+	conn, err := net.Dial("tcp", h.Addr())
+	if err != nil {
+		return "", err //nolint:wrapcheck // dial error.
+	}
+
+	defer conn.Close()
+
+	return h.Client(handshake).RequestResource(conn.LocalAddr().String(), conn) //nolint:wrapcheck // service error.
+}
+
+// Connect - request resource by client app with retries from harness options.
+func (h *Harness) Connect(handshake bool) error {
+	return client.Connect(client.Opts{ //nolint:wrapcheck // client error.
+		Config:  h.config,
+		Logger:  h.logger,
+		Service: h.Client(handshake),
+	})
+}
+
+// Dial - open raw connection to send arbitrary messages, connection is closed on test cleanup.
+func (h *Harness) Dial() *Conn {
+	h.t.Helper()
+
+	conn, err := net.Dial("tcp", h.Addr())
+	require.NoError(h.t, err)
+
+	h.t.Cleanup(func() { conn.Close() })
+
+	return newConn(h.t, conn, h.config.opts.ComputeMaxAttempts)
+}
+
+// WaitConnections - wait until server tracks n active connections.
+func (h *Harness) WaitConnections(n int) {
+	h.t.Helper()
+
+	require.Eventually(h.t, func() bool { return len(h.Server.Connections()) == n },
+		time.Second, time.Millisecond, "expected %d connections", n)
+}
+
+// Shutdown - shut down server once, next calls return the first report.
+func (h *Harness) Shutdown() server.ShutdownReport {
+	h.shutdownOnce.Do(func() {
+		h.report = h.Server.Shutdown()
+		h.cancel()
+	})
+
+	return h.report
+}
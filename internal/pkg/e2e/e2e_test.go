@@ -0,0 +1,253 @@
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/service"
+)
+
+func Test_Success(t *testing.T) {
+	h := Start(t, Opts{Resources: []string{"first", "second"}})
+
+	t.Run("client without handshake", func(t *testing.T) {
+		resource, err := h.Request(false)
+		require.NoError(t, err)
+		require.Contains(t, []string{"first", "second"}, resource)
+	})
+
+	t.Run("client with handshake", func(t *testing.T) {
+		resource, err := h.Request(true)
+		require.NoError(t, err)
+		require.Contains(t, []string{"first", "second"}, resource)
+	})
+
+	t.Run("client app", func(t *testing.T) {
+		require.NoError(t, h.Connect(true))
+	})
+
+	t.Run("solution is accepted after re-requested puzzle", func(t *testing.T) {
+		conn := h.Dial()
+
+		conn.RequestPuzzle()
+		msg := conn.RequestResource(conn.Solve(conn.RequestPuzzle()))
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+}
+
+func Test_Expiry(t *testing.T) {
+	fakeClock := clock.NewFake(time.Now())
+	h := Start(t, Opts{Clock: fakeClock, PuzzleTTL: time.Minute})
+
+	t.Run("solution before ttl is accepted", func(t *testing.T) {
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		fakeClock.Advance(time.Minute - time.Second)
+
+		msg := conn.RequestResource(solution)
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+
+	t.Run("solution after ttl is rejected", func(t *testing.T) {
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		fakeClock.Advance(time.Minute)
+
+		msg := conn.RequestResource(solution)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrHashcashHeaderNotFound)
+		conn.RequireClosed()
+	})
+}
+
+func Test_ClockSkew(t *testing.T) {
+	t.Run("puzzle dated ahead of server clock is rejected", func(t *testing.T) {
+		fakeClock := clock.NewFake(time.Now())
+		h := Start(t, Opts{Clock: fakeClock})
+
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		fakeClock.Advance(-30 * time.Second)
+
+		msg := conn.RequestResource(solution)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrPuzzleDateInFuture)
+	})
+
+	t.Run("puzzle dated ahead within skew is accepted", func(t *testing.T) {
+		fakeClock := clock.NewFake(time.Now())
+		h := Start(t, Opts{Clock: fakeClock, ClockSkew: time.Minute})
+
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		fakeClock.Advance(-30 * time.Second)
+
+		msg := conn.RequestResource(solution)
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+}
+
+func Test_Replay(t *testing.T) {
+	h := Start(t, Opts{})
+
+	t.Run("solution is accepted once", func(t *testing.T) {
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		msg := conn.RequestResource(solution)
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+
+		replay := h.Dial()
+		replay.RequestPuzzle()
+
+		msg = replay.RequestResource(solution)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrHashcashHeaderNotFound)
+	})
+
+	t.Run("solution of another client is rejected", func(t *testing.T) {
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		thief := h.Dial()
+		thief.RequestPuzzle()
+
+		msg := thief.RequestResource(solution)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrHashcashHeaderNotFound)
+
+		msg = conn.RequestResource(solution)
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+}
+
+func Test_Timeout(t *testing.T) {
+	h := Start(t, Opts{ConnectionTimeout: 100 * time.Millisecond})
+
+	t.Run("silent client", func(t *testing.T) {
+		conn := h.Dial()
+
+		msg, err := conn.Receive()
+		require.NoError(t, err)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrTimeoutExceeded)
+		conn.RequireClosed()
+	})
+
+	t.Run("client doesn't send solution", func(t *testing.T) {
+		conn := h.Dial()
+		conn.RequestPuzzle()
+
+		msg, err := conn.Receive()
+		require.NoError(t, err)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrTimeoutExceeded)
+	})
+
+	t.Run("client app gets resource before timeout", func(t *testing.T) {
+		require.NoError(t, h.Connect(false))
+	})
+}
+
+func Test_MalformedInput(t *testing.T) {
+	h := Start(t, Opts{ViolationBanThreshold: 100})
+
+	t.Run("garbage closes connection", func(t *testing.T) {
+		conn := h.Dial()
+		conn.SendRaw("garbage\n")
+
+		msg, err := conn.Receive()
+		require.NoError(t, err)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrIncorrectMessageFormat)
+		conn.RequireClosed()
+	})
+
+	t.Run("unsupported command keeps connection", func(t *testing.T) {
+		conn := h.Dial()
+
+		msg := conn.Exchange(message.Message{Command: 42, Payload: "ping"})
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrUnsupportedCommand)
+
+		msg = conn.RequestResource(conn.Solve(conn.RequestPuzzle()))
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+
+	t.Run("incorrect solution", func(t *testing.T) {
+		conn := h.Dial()
+		conn.RequestPuzzle()
+
+		msg := conn.RequestResource("hashcash 1:1:not-a-header")
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrHashcashHeaderNotCorrect)
+	})
+
+	t.Run("server command is protocol violation", func(t *testing.T) {
+		conn := h.Dial()
+
+		msg := conn.Exchange(message.Message{Command: message.CommandResponseResource, Payload: "resource"})
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrProtocolViolation)
+		conn.RequireClosed()
+		require.Positive(t, h.Penalties.Stats().Total[service.ErrProtocolViolation.Error()])
+	})
+
+	t.Run("client with unsupported scheme fails", func(t *testing.T) {
+		conn := h.Dial()
+
+		msg := conn.Exchange(message.Message{Command: message.CommandRequestPuzzle, Payload: "unknown"})
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrUnsupportedPuzzleScheme)
+	})
+}
+
+func Test_Shutdown(t *testing.T) {
+	t.Run("idle session is drained and in-flight session is killed", func(t *testing.T) {
+		h := Start(t, Opts{ShutdownTimeout: 100 * time.Millisecond})
+
+		inFlight := h.Dial()
+		inFlight.RequestPuzzle()
+
+		idle := h.Dial()
+		h.WaitConnections(2)
+
+		report := h.Shutdown()
+		require.Equal(t, 1, report.Drained)
+		require.Equal(t, 1, report.Killed)
+
+		msg, err := idle.Receive()
+		require.NoError(t, err)
+		require.ErrorIs(t, service.ErrCheckResMessage(msg), service.ErrServerShuttingDown)
+
+		inFlight.RequireClosed()
+
+		_, err = h.Request(false)
+		require.Error(t, err)
+	})
+
+	t.Run("in-flight session finishes before shutdown timeout", func(t *testing.T) {
+		h := Start(t, Opts{ShutdownTimeout: time.Second})
+
+		conn := h.Dial()
+		solution := conn.Solve(conn.RequestPuzzle())
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			h.Shutdown()
+		}()
+
+		msg := conn.RequestResource(solution)
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+
+		<-done
+		require.Equal(t, 1, h.Shutdown().Drained)
+		require.Zero(t, h.Shutdown().Killed)
+	})
+
+	t.Run("client app fails after retries when server is stopped", func(t *testing.T) {
+		h := Start(t, Opts{MaxRetries: 2})
+		h.Shutdown()
+
+		require.Error(t, h.Connect(false))
+	})
+}
@@ -0,0 +1,119 @@
+package banlist
+
+import (
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+)
+
+// New - create new empty ban list.
+func New() *Banlist {
+	return &Banlist{
+		prefixes: make(map[netip.Prefix]struct{}),
+	}
+}
+
+// Banlist - thread-safe list of banned ip prefixes.
+type Banlist struct {
+	prefixes map[netip.Prefix]struct{}
+	mu       sync.RWMutex
+}
+
+// Ban - ban ip prefix in CIDR notation or single ip address.
+func (b *Banlist) Ban(prefix string) error {
+	p, err := ParsePrefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.prefixes[p] = struct{}{}
+
+	return nil
+}
+
+// Unban - remove ip prefix from ban list.
+func (b *Banlist) Unban(prefix string) error {
+	p, err := ParsePrefix(prefix)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.prefixes[p]; !ok {
+		return ErrPrefixNotFound
+	}
+
+	delete(b.prefixes, p)
+
+	return nil
+}
+
+// Prefixes - returns sorted banned prefixes.
+func (b *Banlist) Prefixes() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	prefixes := make([]string, 0, len(b.prefixes))
+	for p := range b.prefixes {
+		prefixes = append(prefixes, p.String())
+	}
+
+	slices.Sort(prefixes)
+
+	return prefixes
+}
+
+// IsBanned - check if address is banned.
+// Address could be ip or host:port.
+func (b *Banlist) IsBanned(addr string) bool {
+	ip, ok := parseAddr(addr)
+	if !ok {
+		return false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for p := range b.prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParsePrefix - parse ip prefix in CIDR notation or single ip address.
+func ParsePrefix(prefix string) (netip.Prefix, error) {
+	if ip, err := netip.ParseAddr(prefix); err == nil {
+		ip = ip.Unmap()
+
+		return netip.PrefixFrom(ip, ip.BitLen()), nil
+	}
+
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return p, ErrIncorrectPrefix
+	}
+
+	return p.Masked(), nil
+}
+
+func parseAddr(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return ip, false
+	}
+
+	return ip.Unmap(), true
+}
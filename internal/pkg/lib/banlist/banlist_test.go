@@ -0,0 +1,36 @@
+package banlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Banlist(t *testing.T) {
+	t.Run("ban and unban ok", func(t *testing.T) {
+		b := New()
+
+		require.NoError(t, b.Ban("10.0.0.0/8"))
+		require.NoError(t, b.Ban("192.168.1.1"))
+		require.NoError(t, b.Ban("10.1.2.3/8"))
+		require.Equal(t, []string{"10.0.0.0/8", "192.168.1.1/32"}, b.Prefixes())
+
+		require.True(t, b.IsBanned("10.20.30.40:1234"))
+		require.True(t, b.IsBanned("192.168.1.1"))
+		require.False(t, b.IsBanned("192.168.1.2:1234"))
+		require.False(t, b.IsBanned("[::1]:1234"))
+
+		require.NoError(t, b.Unban("10.0.0.0/8"))
+		require.False(t, b.IsBanned("10.20.30.40:1234"))
+		require.Equal(t, []string{"192.168.1.1/32"}, b.Prefixes())
+	})
+
+	t.Run("ban and unban failed", func(t *testing.T) {
+		b := New()
+
+		require.ErrorIs(t, b.Ban("10.0.0.0/33"), ErrIncorrectPrefix)
+		require.ErrorIs(t, b.Ban("host"), ErrIncorrectPrefix)
+		require.ErrorIs(t, b.Unban("10.0.0.0/8"), ErrPrefixNotFound)
+		require.False(t, b.IsBanned("incorrect address"))
+	})
+}
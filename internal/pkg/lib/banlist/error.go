@@ -0,0 +1,8 @@
+package banlist
+
+import "errors"
+
+var (
+	ErrIncorrectPrefix = errors.New("incorrect ip prefix")
+	ErrPrefixNotFound  = errors.New("prefix not found")
+)
@@ -4,13 +4,17 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
 )
 
 // Opts - options to create new cache instance.
 // CleanInterval - uses if value > 0.
+// Clock - optional, system clock is used by default.
 type Opts struct {
 	CleanInterval time.Duration
 	Logger        Logger
+	Clock         clock.Clock
 }
 
 // New - create new expirable cache instance with clean interval in ms.
@@ -18,6 +22,7 @@ func New[K comparable, V any](ctx context.Context, opts Opts) *Cache[K, V] {
 	c := &Cache[K, V]{
 		cache:  make(map[K]value[V]),
 		logger: opts.Logger,
+		clock:  clock.OrReal(opts.Clock),
 	}
 	if opts.CleanInterval > 0 {
 		go c.runCleaner(ctx, opts.CleanInterval)
@@ -31,6 +36,7 @@ type Cache[K comparable, V any] struct {
 	cache  map[K]value[V]
 	mu     sync.RWMutex
 	logger Logger
+	clock  clock.Clock
 }
 
 // AddWithExp - add value by key with time expiration.
@@ -68,7 +74,7 @@ func (c *Cache[K, V]) Get(k K) (v V, ok bool) {
 	defer c.mu.Unlock()
 
 	value, ok := c.cache[k]
-	if ok && value.actual() {
+	if ok && value.actual(c.clock.Now()) {
 		v, ok = value.data, true
 
 		return
@@ -82,8 +88,9 @@ func (c *Cache[K, V]) Keys() (keys []K) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	now := c.clock.Now()
 	for k, v := range c.cache {
-		if v.actual() {
+		if v.actual(now) {
 			keys = append(keys, k)
 		}
 	}
@@ -96,8 +103,9 @@ func (c *Cache[K, V]) Values() (values []V) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	now := c.clock.Now()
 	for _, v := range c.cache {
-		if v.actual() {
+		if v.actual(now) {
 			values = append(values, v.data)
 		}
 	}
@@ -105,13 +113,37 @@ func (c *Cache[K, V]) Values() (values []V) {
 	return
 }
 
+// Stats - cache statistics.
+type Stats struct {
+	Size    int `json:"size"`    // number of stored values, including expired.
+	Expired int `json:"expired"` // number of expired values waiting for cleaning.
+}
+
+// Stats - get cache statistics.
+func (c *Cache[K, V]) Stats() (stats Stats) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.clock.Now()
+
+	stats.Size = len(c.cache)
+	for _, v := range c.cache {
+		if !v.actual(now) {
+			stats.Expired++
+		}
+	}
+
+	return
+}
+
 // ClearExpired - clear expired keys.
 func (c *Cache[K, V]) ClearExpired() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	now := c.clock.Now()
 	for k, v := range c.cache {
-		if !v.actual() {
+		if !v.actual(now) {
 			delete(c.cache, k)
 		}
 	}
@@ -141,6 +173,6 @@ type value[V any] struct {
 	exp  int64
 }
 
-func (v value[V]) actual() bool {
-	return v.exp == 0 || time.Now().UnixNano() < v.exp
+func (v value[V]) actual(now time.Time) bool {
+	return v.exp == 0 || now.UnixNano() < v.exp
 }
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
 )
 
 func Test_Cache(t *testing.T) {
@@ -32,6 +34,7 @@ func Test_Cache(t *testing.T) {
 		// Values must be not actual but be in cache.
 		time.Sleep(200 * time.Millisecond)
 		require.Equal(t, 2, len(c.cache))
+		require.Equal(t, Stats{Size: 2, Expired: 2}, c.Stats())
 
 		act, ok = c.Get("1")
 		require.Equal(t, "", act)
@@ -51,4 +54,34 @@ func Test_Cache(t *testing.T) {
 		time.Sleep(50 * time.Millisecond)
 		require.True(t, logger.cancelSignalHandled)
 	})
+
+	t.Run("expiration by fake clock", func(t *testing.T) {
+		fakeClock := clock.NewFake(time.Now())
+
+		c := New[string, int](context.Background(), Opts{
+			Logger: &mockLogger{},
+			Clock:  fakeClock,
+		})
+
+		c.AddWithExp("expirable", 1, fakeClock.Now().Add(time.Minute))
+		c.Add("permanent", 2)
+
+		fakeClock.Advance(time.Minute - time.Nanosecond)
+
+		v, ok := c.Get("expirable")
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+		require.ElementsMatch(t, []string{"expirable", "permanent"}, c.Keys())
+
+		fakeClock.Advance(time.Nanosecond)
+
+		_, ok = c.Get("expirable")
+		require.False(t, ok)
+		require.Equal(t, []string{"permanent"}, c.Keys())
+		require.Equal(t, []int{2}, c.Values())
+		require.Equal(t, Stats{Size: 2, Expired: 1}, c.Stats())
+
+		c.ClearExpired()
+		require.Equal(t, Stats{Size: 1}, c.Stats())
+	})
 }
@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock - source of current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real - system clock.
+type Real struct{}
+
+// Now - returns current system time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// OrReal - returns c or system clock if c is nil.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+
+	return c
+}
+
+// NewFake - create new fake clock stopped at now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Fake - thread-safe controllable clock for tests, time goes only by Set and Advance.
+type Fake struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// Now - returns fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.now
+}
+
+// Set - set fake current time.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+}
+
+// Advance - move fake current time by d, negative d moves it back.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
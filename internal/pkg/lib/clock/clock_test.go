@@ -0,0 +1,37 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Fake(t *testing.T) {
+	t.Run("time goes only by set and advance", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		c := NewFake(start)
+
+		require.Equal(t, start, c.Now())
+
+		c.Advance(time.Minute)
+		require.Equal(t, start.Add(time.Minute), c.Now())
+
+		c.Advance(-2 * time.Minute)
+		require.Equal(t, start.Add(-time.Minute), c.Now())
+
+		c.Set(start)
+		require.Equal(t, start, c.Now())
+	})
+}
+
+func Test_OrReal(t *testing.T) {
+	t.Run("nil clock is system clock", func(t *testing.T) {
+		require.Equal(t, Real{}, OrReal(nil))
+	})
+
+	t.Run("clock is kept", func(t *testing.T) {
+		c := NewFake(time.Time{})
+		require.Same(t, c, OrReal(c))
+	})
+}
@@ -3,47 +3,126 @@ package config
 import (
 	"flag"
 	"fmt"
+	"math"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
 )
 
 // Config - config structure.
 type Config struct {
-	Server   `yaml:"server" env-prefix:"SERVER_"`
-	Client   `yaml:"client" env-prefix:"CLIENT_"`
-	Hashcash `yaml:"hashcash" env-prefix:"HASHCASH_"`
+	Server   `yaml:"server" json:"server" env-prefix:"SERVER_"`
+	Client   `yaml:"client" json:"client" env-prefix:"CLIENT_"`
+	Hashcash `yaml:"hashcash" json:"hashcash" env-prefix:"HASHCASH_"`
 }
 
 // Server - server config structure.
+// Fields with reload:"restart" tag could not be changed without restart.
 type Server struct {
-	LogLevel          int    `yaml:"log_level" env:"LOG_LEVEL" env-default:"0"`
-	LogJSON           bool   `yaml:"log_json" env:"LOG_JSON" env-default:"false"`
-	Address           string `yaml:"address" env:"ADDRESS" env-default:":8080"`
-	ShutdownTimeout   int    `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"1000"`
-	ConnectionTimeout int    `yaml:"connection_timeout" env:"CONNECTION_TIMEOUT" env-default:"30000"`
+	LogLevel                int    `yaml:"log_level" json:"log_level" env:"LOG_LEVEL" env-default:"0" reload:"restart"`
+	LogJSON                 bool   `yaml:"log_json" json:"log_json" env:"LOG_JSON" env-default:"false" reload:"restart"`
+	Address                 string `yaml:"address" json:"address" env:"ADDRESS" env-default:":8080" reload:"restart"`
+	ShutdownTimeout         int    `yaml:"shutdown_timeout" json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"1000"`
+	ConnectionTimeout       int    `yaml:"connection_timeout" json:"connection_timeout" env:"CONNECTION_TIMEOUT" env-default:"30000"`
+	ReadTimeout             int    `yaml:"read_timeout" json:"read_timeout" env:"READ_TIMEOUT" env-default:"0"`
+	Workers                 int    `yaml:"workers" json:"workers" env:"WORKERS" env-default:"0" reload:"restart"`
+	QueueSize               int    `yaml:"queue_size" json:"queue_size" env:"QUEUE_SIZE" env-default:"0" reload:"restart"`
+	QueueTimeout            int    `yaml:"queue_timeout" json:"queue_timeout" env:"QUEUE_TIMEOUT" env-default:"100"`
+	BusyRetryAfter          int    `yaml:"busy_retry_after" json:"busy_retry_after" env:"BUSY_RETRY_AFTER" env-default:"1000"`
+	AdminAddress            string `yaml:"admin_address" json:"admin_address" env:"ADMIN_ADDRESS" env-default:"" reload:"restart"`
+	ResourcesFile           string `yaml:"resources_file" json:"resources_file" env:"RESOURCES_FILE" env-default:""`
+	PuzzleClearInterval     int    `yaml:"puzzle_clear_interval" json:"puzzle_clear_interval" env:"PUZZLE_CLEAR_INTERVAL" env-default:"0" reload:"restart"`
+	WatchInterval           int    `yaml:"config_watch_interval" json:"config_watch_interval" env:"CONFIG_WATCH_INTERVAL" env-default:"0" reload:"restart"`
+	ViolationBanThreshold   int    `yaml:"violation_ban_threshold" json:"violation_ban_threshold" env:"VIOLATION_BAN_THRESHOLD" env-default:"0" reload:"restart"`
+	MaxPuzzlesPerConnection int    `yaml:"max_puzzles_per_connection" json:"max_puzzles_per_connection" env:"MAX_PUZZLES_PER_CONNECTION" env-default:"3"`
+	ViolationWindow         int    `yaml:"violation_window" json:"violation_window" env:"VIOLATION_WINDOW" env-default:"600000" reload:"restart"`
 }
 
 // Client - client config structure.
 type Client struct {
-	LogLevel      int    `yaml:"log_level" env:"LOG_LEVEL" env-default:"0"`
-	LogJSON       bool   `yaml:"log_json" env:"LOG_JSON" env-default:"false"`
-	ServerAddress string `yaml:"server_address" env:"SERVER_ADDRESS" env-default:":8080"`
+	LogLevel       int    `yaml:"log_level" json:"log_level" env:"LOG_LEVEL" env-default:"0"`
+	LogJSON        bool   `yaml:"log_json" json:"log_json" env:"LOG_JSON" env-default:"false"`
+	ServerAddress  string `yaml:"server_address" json:"server_address" env:"SERVER_ADDRESS" env-default:":8080"`
+	MaxRetries     int    `yaml:"max_retries" json:"max_retries" env:"MAX_RETRIES" env-default:"3"`
+	RetryBaseDelay int    `yaml:"retry_base_delay" json:"retry_base_delay" env:"RETRY_BASE_DELAY" env-default:"100"`
+	RetryMaxDelay  int    `yaml:"retry_max_delay" json:"retry_max_delay" env:"RETRY_MAX_DELAY" env-default:"5000"`
+	Handshake      bool   `yaml:"handshake" json:"handshake" env:"HANDSHAKE" env-default:"false"`
 }
 
 // Hashcash - Hashcash config structure.
 type Hashcash struct {
-	Bits               int `yaml:"bits" env:"BITS" env-default:"5"`
-	ComputeMaxAttempts int `yaml:"compute_max_attempts"  env:"COMPUTE_MAX_ATTEMPTS" env-default:"100000000"`
-	TTL                int `yaml:"ttl"  env:"TTL" env-default:"60000"`
+	Bits                int    `yaml:"bits" json:"bits" env:"BITS" env-default:"5"`
+	ComputeMaxAttempts  int    `yaml:"compute_max_attempts" json:"compute_max_attempts"  env:"COMPUTE_MAX_ATTEMPTS" env-default:"100000000"`
+	TTL                 int    `yaml:"ttl" json:"ttl"  env:"TTL" env-default:"60000"`
+	ClockSkew           int    `yaml:"clock_skew" json:"clock_skew" env:"CLOCK_SKEW" env-default:"0"`
+	ClientHashRate      int    `yaml:"client_hash_rate" json:"client_hash_rate" env:"CLIENT_HASH_RATE" env-default:"1000000"`
+	SubPuzzles          int    `yaml:"sub_puzzles" json:"sub_puzzles" env:"SUB_PUZZLES" env-default:"0" reload:"restart"`
+	TimeLockIterations  int    `yaml:"timelock_iterations" json:"timelock_iterations" env:"TIMELOCK_ITERATIONS" env-default:"0" reload:"restart"`
+	TimeLockModulusBits int    `yaml:"timelock_modulus_bits" json:"timelock_modulus_bits" env:"TIMELOCK_MODULUS_BITS" env-default:"2048" reload:"restart"`
+	SigningKey          string `yaml:"signing_key" json:"-" env:"SIGNING_KEY" reload:"restart" secret:"true"`
+	SigningKeyID        string `yaml:"signing_key_id" json:"signing_key_id" env:"SIGNING_KEY_ID" env-default:"1" reload:"restart"`
+	VerifyConcurrency   int    `yaml:"verify_concurrency" json:"verify_concurrency" env:"VERIFY_CONCURRENCY" env-default:"0" reload:"restart"`
+	Algorithm           string `yaml:"algorithm" json:"algorithm" env:"ALGORITHM" env-default:"sha256"`
+	Argon2Memory        int    `yaml:"argon2_memory" json:"argon2_memory" env:"ARGON2_MEMORY" env-default:"65536"`
+	Argon2Time          int    `yaml:"argon2_time" json:"argon2_time" env:"ARGON2_TIME" env-default:"1"`
+	Argon2Threads       int    `yaml:"argon2_threads" json:"argon2_threads" env:"ARGON2_THREADS" env-default:"1"`
+	ScryptN             int    `yaml:"scrypt_n" json:"scrypt_n" env:"SCRYPT_N" env-default:"32768"`
+	ScryptR             int    `yaml:"scrypt_r" json:"scrypt_r" env:"SCRYPT_R" env-default:"8"`
+	ScryptP             int    `yaml:"scrypt_p" json:"scrypt_p" env:"SCRYPT_P" env-default:"1"`
+}
+
+// NewAlgorithm - returns hashcash algorithm configured by hashcash config.
+func NewAlgorithm(c Hashcash) (hashcash.Algorithm, error) {
+	switch c.Algorithm {
+	case hashcash.AlgorithmSHA256:
+		return hashcash.SHA256{}, nil
+	case hashcash.AlgorithmArgon2id:
+		if c.Argon2Memory < 0 || c.Argon2Memory > math.MaxUint32 || c.Argon2Time < 0 || c.Argon2Time > math.MaxUint32 ||
+			c.Argon2Threads < 0 || c.Argon2Threads > math.MaxUint8 {
+			return nil, hashcash.ErrIncorrectAlgorithmParams
+		}
+
+		algorithm := hashcash.Argon2id{
+			Memory:  uint32(c.Argon2Memory),
+			Time:    uint32(c.Argon2Time),
+			Threads: uint8(c.Argon2Threads),
+		}
+
+		return algorithm, algorithm.Validate()
+	case hashcash.AlgorithmScrypt:
+		algorithm := hashcash.Scrypt{N: c.ScryptN, R: c.ScryptR, P: c.ScryptP}
+
+		return algorithm, algorithm.Validate()
+	default:
+		return nil, hashcash.ErrUnknownAlgorithm
+	}
 }
 
 // Parse - parse config from file by flag or from env or use default.
 func Parse(flagName string) (*Config, error) {
-	var path string
+	return Load(ParseFlags(flagName).Path)
+}
 
-	flag.StringVar(&path, flagName, "", "")
+// Flags - command line flags.
+// Path - config file path, CheckConfig - only validate config and exit.
+type Flags struct {
+	Path        string
+	CheckConfig bool
+}
+
+// ParseFlags - parse command line flags, config file path is passed by flagName flag.
+func ParseFlags(flagName string) Flags {
+	var flags Flags
+
+	flag.StringVar(&flags.Path, flagName, "", "config file path")
+	flag.BoolVar(&flags.CheckConfig, "check-config", false, "validate config and exit")
 	flag.Parse()
 
+	return flags
+}
+
+// Load - parse config from file if path is not empty or from env or use default.
+func Load(path string) (*Config, error) {
 	if path == "" {
 		return ParseFromEnv()
 	}
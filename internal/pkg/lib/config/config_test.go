@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"testing"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Diff(t *testing.T) {
+	t.Run("diff ok", func(t *testing.T) {
+		oldConfig := &Config{}
+		oldConfig.Server.Address = ":8080"
+		oldConfig.Hashcash.Bits = 5
+
+		newConfig := *oldConfig
+		newConfig.Server.Address = ":9090"
+		newConfig.Hashcash.Bits = 6
+		newConfig.Hashcash.TimeLockIterations = 1000
+		newConfig.Hashcash.SigningKey = "secret"
+
+		require.Equal(t, []Change{
+			{Field: "server.address", Old: ":8080", New: ":9090", Restart: true},
+			{Field: "hashcash.bits", Old: 5, New: 6, Restart: false},
+			{Field: "hashcash.timelock_iterations", Old: 0, New: 1000, Restart: true},
+			{Field: "hashcash.signing_key", Old: "***", New: "***", Restart: true},
+		}, Diff(oldConfig, &newConfig))
+
+		merged := KeepRestartFields(oldConfig, &newConfig)
+		require.Equal(t, ":8080", merged.Server.Address)
+		require.Equal(t, 6, merged.Hashcash.Bits)
+		require.Zero(t, merged.Hashcash.TimeLockIterations)
+		require.Empty(t, Diff(&newConfig, &newConfig))
+	})
+}
+
+func Test_Validate(t *testing.T) {
+	t.Run("default config ok", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+		require.NoError(t, ValidateServer(c))
+	})
+
+	t.Run("all problems reported", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Server.Address = "8080"
+		c.Server.ConnectionTimeout = -1
+		c.Server.QueueSize = 10
+		c.Hashcash.Bits = 65
+
+		err = ValidateServer(c)
+		require.ErrorIs(t, err, ErrIncorrectAddress)
+		require.ErrorIs(t, err, ErrValueNotPositive)
+		require.ErrorIs(t, err, ErrQueueWithoutWorkers)
+		require.ErrorIs(t, err, ErrValueOutOfRange)
+		require.EqualError(t, err, "server.address: incorrect address\n"+
+			"server.connection_timeout: must be more than zero\n"+
+			"server.queue_size: queue requires workers\n"+
+			"hashcash.bits: out of range")
+	})
+
+	t.Run("ttl shorter than solve time", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Hashcash.Bits = 6
+		c.Hashcash.ClientHashRate = 1000
+		c.Hashcash.TTL = 60000
+
+		require.ErrorIs(t, ValidateServer(c), ErrTTLTooShort)
+		require.Equal(t, 16777216*time.Millisecond, ExpectedSolveTime(6, 1000))
+	})
+
+	t.Run("only section of binary is checked", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Server.Address = "8080"
+		c.Client.ServerAddress = "8080"
+
+		require.EqualError(t, ValidateServer(c), "server.address: incorrect address")
+		require.EqualError(t, ValidateClient(c), "client.server_address: incorrect address")
+	})
+
+	t.Run("memory-hard algorithm is slower", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Hashcash.ClientHashRate = 1000000
+		require.Equal(t, 1000000, ClientHashRate(c.Hashcash))
+
+		c.Hashcash.Algorithm = hashcash.AlgorithmArgon2id
+		c.Hashcash.Argon2Memory = 65536
+		c.Hashcash.Argon2Time = 1
+		require.Equal(t, 15, ClientHashRate(c.Hashcash))
+
+		c.Hashcash.Bits = 5
+		require.ErrorIs(t, ValidateServer(c), ErrTTLTooShort)
+
+		c.Hashcash.Bits = 2
+		require.NoError(t, ValidateServer(c))
+
+		c.Hashcash.Algorithm = hashcash.AlgorithmScrypt
+		c.Hashcash.ScryptN = 32768
+		c.Hashcash.ScryptR = 8
+		c.Hashcash.ScryptP = 1
+		require.Equal(t, 30, ClientHashRate(c.Hashcash))
+	})
+
+	t.Run("memory-hard algorithm with high bits", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Hashcash.Algorithm = hashcash.AlgorithmArgon2id
+		c.Hashcash.Bits = 3
+
+		err = ValidateServer(c)
+		require.ErrorIs(t, err, ErrTTLTooShort)
+		require.ErrorContains(t, err, "hashcash.ttl: shorter than expected solve time 4m33.066666666s")
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		c, err := ParseFromEnv()
+		require.NoError(t, err)
+
+		c.Hashcash.TTL = 60000
+		c.Hashcash.ClockSkew = 5000
+		require.NoError(t, ValidateServer(c))
+
+		c.Hashcash.ClockSkew = -1
+		require.ErrorIs(t, ValidateServer(c), ErrValueNegative)
+
+		c.Hashcash.ClockSkew = 60000
+		require.ErrorIs(t, ValidateServer(c), ErrClockSkewTooLong)
+	})
+}
+
+func Test_ValidateFile(t *testing.T) {
+	t.Run("unknown yaml keys reported", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("server:\n  adress: :8080\nhashcash:\n  bitz: 5\n"), 0o600))
+
+		err := ValidateFile(path)
+		require.ErrorIs(t, err, ErrUnknownField)
+		require.EqualError(t, err, "unknown field: line 2: field adress not found in type config.Server\n"+
+			"unknown field: line 4: field bitz not found in type config.Hashcash")
+
+		_, err = Check(path, ValidateServer)
+		require.ErrorIs(t, err, ErrUnknownField)
+	})
+
+	t.Run("env file is not checked", func(t *testing.T) {
+		require.NoError(t, ValidateFile("config.env"))
+	})
+}
+
+func Test_NewAlgorithm(t *testing.T) {
+	t.Run("algorithm ok", func(t *testing.T) {
+		algorithm, err := NewAlgorithm(Hashcash{Algorithm: "argon2id", Argon2Memory: 1024, Argon2Time: 2, Argon2Threads: 1})
+		require.NoError(t, err)
+		require.Equal(t, hashcash.Argon2id{Memory: 1024, Time: 2, Threads: 1}, algorithm)
+
+		algorithm, err = NewAlgorithm(Hashcash{Algorithm: "scrypt", ScryptN: 1024, ScryptR: 8, ScryptP: 1})
+		require.NoError(t, err)
+		require.Equal(t, hashcash.Scrypt{N: 1024, R: 8, P: 1}, algorithm)
+	})
+
+	t.Run("algorithm failed", func(t *testing.T) {
+		_, err := NewAlgorithm(Hashcash{Algorithm: "md5"})
+		require.ErrorIs(t, err, hashcash.ErrUnknownAlgorithm)
+
+		_, err = NewAlgorithm(Hashcash{Algorithm: "argon2id", Argon2Memory: -1, Argon2Time: 1, Argon2Threads: 1})
+		require.ErrorIs(t, err, hashcash.ErrIncorrectAlgorithmParams)
+
+		_, err = NewAlgorithm(Hashcash{Algorithm: "scrypt", ScryptN: 1000, ScryptR: 8, ScryptP: 1})
+		require.ErrorIs(t, err, hashcash.ErrIncorrectAlgorithmParams)
+	})
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// secretMask - value shown instead of secret field value.
+const secretMask = "***"
+
+// Change - changed config field.
+// Field - field path by yaml names, e.g. "hashcash.bits".
+// Values of fields tagged with secret:"true" are masked.
+type Change struct {
+	Field   string
+	Old     any
+	New     any
+	Restart bool // change requires restart.
+}
+
+// Diff - returns changed fields between old and new config.
+func Diff(oldConfig, newConfig *Config) []Change {
+	return diff("", reflect.ValueOf(*oldConfig), reflect.ValueOf(*newConfig), nil)
+}
+
+// KeepRestartFields - returns copy of new config with fields requiring restart taken from old config.
+func KeepRestartFields(oldConfig, newConfig *Config) *Config {
+	merged := *newConfig
+	keepRestart(reflect.ValueOf(*oldConfig), reflect.ValueOf(&merged).Elem())
+
+	return &merged
+}
+
+func diff(prefix string, oldValue, newValue reflect.Value, changes []Change) []Change {
+	for i := range oldValue.NumField() {
+		field := oldValue.Type().Field(i)
+		name := fieldName(prefix, field)
+
+		if field.Type.Kind() == reflect.Struct {
+			changes = diff(name, oldValue.Field(i), newValue.Field(i), changes)
+
+			continue
+		}
+
+		if oldValue.Field(i).Equal(newValue.Field(i)) {
+			continue
+		}
+
+		change := Change{
+			Field:   name,
+			Old:     oldValue.Field(i).Interface(),
+			New:     newValue.Field(i).Interface(),
+			Restart: field.Tag.Get("reload") == "restart",
+		}
+
+		if field.Tag.Get("secret") == "true" {
+			change.Old, change.New = secretMask, secretMask
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func keepRestart(oldValue, newValue reflect.Value) {
+	for i := range oldValue.NumField() {
+		field := oldValue.Type().Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			keepRestart(oldValue.Field(i), newValue.Field(i))
+
+			continue
+		}
+
+		if field.Tag.Get("reload") == "restart" {
+			newValue.Field(i).Set(oldValue.Field(i))
+		}
+	}
+}
+
+func fieldName(prefix string, field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
@@ -0,0 +1,15 @@
+package config
+
+import "errors"
+
+var (
+	ErrValueNotPositive      = errors.New("must be more than zero")
+	ErrValueNegative         = errors.New("must not be negative")
+	ErrValueOutOfRange       = errors.New("out of range")
+	ErrIncorrectAddress      = errors.New("incorrect address")
+	ErrUnknownField          = errors.New("unknown field")
+	ErrQueueWithoutWorkers   = errors.New("queue requires workers")
+	ErrTTLTooShort           = errors.New("shorter than expected solve time")
+	ErrRetryMaxDelayTooShort = errors.New("must not be less than retry base delay")
+	ErrClockSkewTooLong      = errors.New("must be shorter than ttl")
+)
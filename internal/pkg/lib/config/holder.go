@@ -0,0 +1,52 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// NewHolder - create new config holder with initial config, changed config is checked by validate.
+func NewHolder(c *Config, validate func(c *Config) error) *Holder {
+	h := &Holder{validate: validate}
+	h.current.Store(c)
+
+	return h
+}
+
+// Holder - holds current config and allows to replace it atomically.
+// Stored config must not be modified, use Update or Store to change it.
+type Holder struct {
+	current  atomic.Pointer[Config]
+	mu       sync.Mutex
+	validate func(c *Config) error
+}
+
+// Load - returns current config.
+func (h *Holder) Load() *Config {
+	return h.current.Load()
+}
+
+// Store - replace current config.
+func (h *Holder) Store(c *Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.current.Store(c)
+}
+
+// Update - apply changes to copy of current config and store it if changed config is valid.
+func (h *Holder) Update(update func(c *Config)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := *h.current.Load()
+	update(&c)
+
+	if err := h.validate(&c); err != nil {
+		return err
+	}
+
+	h.current.Store(&c)
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	minLogLevel     = -4
+	maxLogLevel     = 8
+	maxHashcashBits = 64
+	unixPrefix      = "unix:"
+	// scryptBlockSize - scrypt fills memory with r*N blocks of 128 bytes.
+	scryptBlockSize = 128
+	kib             = 1024
+)
+
+// Check - load config and validate it by validate, returns all found problems at once.
+func Check(path string, validate func(c *Config) error) (*Config, error) {
+	config, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = errors.Join(ValidateFile(path), validate(config)); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ValidateFile - check that yaml config file doesn't contain unknown keys.
+// Other file formats are not checked.
+func ValidateFile(path string) error {
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	var typeErr *yaml.TypeError
+	if err = decoder.Decode(&Config{}); errors.As(err, &typeErr) {
+		errs := make([]error, 0, len(typeErr.Errors))
+		for _, e := range typeErr.Errors {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownField, e))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// ValidateServer - check server and hashcash config values and cross-field rules,
+// returns all found problems at once.
+func ValidateServer(c *Config) error {
+	var v validator
+
+	v.check("server.log_level", c.Server.LogLevel >= minLogLevel && c.Server.LogLevel <= maxLogLevel, ErrValueOutOfRange)
+	v.check("server.address", isAddress(c.Server.Address), ErrIncorrectAddress)
+	v.check("server.shutdown_timeout", c.Server.ShutdownTimeout > 0, ErrValueNotPositive)
+	v.check("server.connection_timeout", c.Server.ConnectionTimeout > 0, ErrValueNotPositive)
+	v.check("server.read_timeout", c.Server.ReadTimeout >= 0, ErrValueNegative)
+	v.check("server.workers", c.Server.Workers >= 0, ErrValueNegative)
+	v.check("server.queue_size", c.Server.QueueSize >= 0, ErrValueNegative)
+	v.check("server.queue_size", c.Server.QueueSize == 0 || c.Server.Workers > 0, ErrQueueWithoutWorkers)
+	v.check("server.queue_timeout", c.Server.QueueTimeout >= 0, ErrValueNegative)
+	v.check("server.busy_retry_after", c.Server.BusyRetryAfter >= 0, ErrValueNegative)
+	v.check("server.admin_address", c.Server.AdminAddress == "" || isAdminAddress(c.Server.AdminAddress), ErrIncorrectAddress)
+	v.check("server.config_watch_interval", c.Server.WatchInterval >= 0, ErrValueNegative)
+	v.check("server.puzzle_clear_interval", c.Server.PuzzleClearInterval >= 0, ErrValueNegative)
+	v.check("server.max_puzzles_per_connection", c.Server.MaxPuzzlesPerConnection >= 0, ErrValueNegative)
+	v.check("server.violation_ban_threshold", c.Server.ViolationBanThreshold >= 0, ErrValueNegative)
+	v.check("server.violation_window", c.Server.ViolationWindow > 0, ErrValueNotPositive)
+
+	v.check("hashcash.bits", c.Hashcash.Bits > 0 && c.Hashcash.Bits <= maxHashcashBits, ErrValueOutOfRange)
+	v.check("hashcash.compute_max_attempts", c.Hashcash.ComputeMaxAttempts > 0, ErrValueNotPositive)
+	v.check("hashcash.client_hash_rate", c.Hashcash.ClientHashRate > 0, ErrValueNotPositive)
+	v.check("hashcash.ttl", c.Hashcash.TTL > 0, ErrValueNotPositive)
+	v.check("hashcash.clock_skew", c.Hashcash.ClockSkew >= 0, ErrValueNegative)
+	v.check("hashcash.clock_skew", c.Hashcash.ClockSkew < c.Hashcash.TTL || c.Hashcash.TTL <= 0, ErrClockSkewTooLong)
+	v.check("hashcash.sub_puzzles", c.Hashcash.SubPuzzles >= 0, ErrValueNegative)
+	v.check("hashcash.timelock_iterations", c.Hashcash.TimeLockIterations >= 0, ErrValueNegative)
+	v.check("hashcash.timelock_modulus_bits", c.Hashcash.TimeLockModulusBits > 0, ErrValueNotPositive)
+	v.check("hashcash.verify_concurrency", c.Hashcash.VerifyConcurrency >= 0, ErrValueNegative)
+
+	if _, err := NewAlgorithm(c.Hashcash); err != nil {
+		v.check("hashcash.algorithm", false, err)
+	} else if c.Hashcash.Bits > 0 && c.Hashcash.Bits <= maxHashcashBits && c.Hashcash.ClientHashRate > 0 && c.Hashcash.TTL > 0 {
+		solveTime := ExpectedSolveTime(c.Hashcash.Bits, ClientHashRate(c.Hashcash))
+		v.check("hashcash.ttl", time.Duration(c.Hashcash.TTL)*time.Millisecond > solveTime,
+			fmt.Errorf("%w %s", ErrTTLTooShort, solveTime))
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// ValidateClient - check client config values and hashcash values used by client,
+// returns all found problems at once.
+func ValidateClient(c *Config) error {
+	var v validator
+
+	v.check("client.log_level", c.Client.LogLevel >= minLogLevel && c.Client.LogLevel <= maxLogLevel, ErrValueOutOfRange)
+	v.check("client.server_address", isAddress(c.Client.ServerAddress), ErrIncorrectAddress)
+	v.check("client.max_retries", c.Client.MaxRetries >= 0, ErrValueNegative)
+	v.check("client.retry_base_delay", c.Client.RetryBaseDelay >= 0, ErrValueNegative)
+	v.check("client.retry_max_delay", c.Client.RetryMaxDelay >= c.Client.RetryBaseDelay, ErrRetryMaxDelayTooShort)
+
+	v.check("hashcash.compute_max_attempts", c.Hashcash.ComputeMaxAttempts > 0, ErrValueNotPositive)
+
+	return errors.Join(v.errs...)
+}
+
+// ClientHashRate - returns expected hash rate of legitimate clients with configured algorithm.
+// client_hash_rate is sha256 hash rate, memory-hard hash is expected to cost
+// as many sha256 hashes as KiB of memory it fills, but at least one hash per second.
+func ClientHashRate(c Hashcash) int {
+	var cost float64
+
+	switch c.Algorithm {
+	case hashcash.AlgorithmArgon2id:
+		cost = float64(c.Argon2Memory) * float64(c.Argon2Time)
+	case hashcash.AlgorithmScrypt:
+		cost = float64(scryptBlockSize) * float64(c.ScryptR) * float64(c.ScryptN) * float64(c.ScryptP) / kib
+	default:
+		return c.ClientHashRate
+	}
+
+	return max(int(float64(c.ClientHashRate)/max(cost, 1)), 1)
+}
+
+// ExpectedSolveTime - returns expected time to solve puzzle with bits zero bits at hash rate per second.
+func ExpectedSolveTime(bits, hashRate int) time.Duration {
+	seconds := hashcash.ExpectedAttempts(bits) / float64(hashRate)
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(field string, ok bool, err error) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %w", field, err))
+	}
+}
+
+func isAddress(address string) bool {
+	_, _, err := net.SplitHostPort(address)
+
+	return err == nil
+}
+
+func isAdminAddress(address string) bool {
+	if path, ok := strings.CutPrefix(address, unixPrefix); ok {
+		return path != ""
+	}
+
+	return isAddress(address)
+}
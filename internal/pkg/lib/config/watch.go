@@ -0,0 +1,33 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch - call onChange every time config file modification time or size is changed.
+// File is checked by polling with interval, blocks until context is canceled.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, _ := os.Stat(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+
+			if last == nil || !current.ModTime().Equal(last.ModTime()) || current.Size() != last.Size() {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package hashcash
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Algorithm IDs.
+const (
+	AlgorithmSHA256   = "sha256"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+)
+
+// Memory-hard algorithms parameters limits.
+// Parameters are taken from header, so they are bounded to keep computing cost predictable.
+const (
+	MaxArgon2Memory  = 1 << 20 // in KiB, 1 GiB.
+	MaxArgon2Time    = 16
+	MaxArgon2Threads = 64
+	MaxScryptN       = 1 << 20
+	MaxScryptR       = 32
+	MaxScryptP       = 16
+
+	keyLength = 32
+)
+
+// salt - fixed salt for memory-hard algorithms, header has own random field.
+var salt = []byte("hashcash") //nolint:gochecknoglobals // constant.
+
+// Algorithm - hash function to compute and verify puzzle.
+type Algorithm interface {
+	// ID - algorithm identifier.
+	ID() string
+	// Params - algorithm parameters as extension fields, empty for algorithms without parameters.
+	Params() Extension
+	// Sum - returns hash of data.
+	Sum(data []byte) ([]byte, error)
+}
+
+// SHA256 - default algorithm.
+type SHA256 struct{}
+
+// ID - algorithm identifier.
+func (SHA256) ID() string {
+	return AlgorithmSHA256
+}
+
+// Params - algorithm has no parameters.
+func (SHA256) Params() Extension {
+	return nil
+}
+
+// Sum - returns sha256 hash of data.
+func (SHA256) Sum(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+
+	return hash[:], nil
+}
+
+// Argon2id - memory-hard algorithm.
+// Memory - in KiB.
+type Argon2id struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// ID - algorithm identifier.
+func (a Argon2id) ID() string {
+	return AlgorithmArgon2id
+}
+
+// Params - algorithm parameters.
+func (a Argon2id) Params() Extension {
+	return Extension{
+		{Name: "m", Values: []string{strconv.FormatUint(uint64(a.Memory), 10)}},
+		{Name: "t", Values: []string{strconv.FormatUint(uint64(a.Time), 10)}},
+		{Name: "p", Values: []string{strconv.FormatUint(uint64(a.Threads), 10)}},
+	}
+}
+
+// Sum - returns argon2id key of data.
+func (a Argon2id) Sum(data []byte) ([]byte, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	return argon2.IDKey(data, salt, a.Time, a.Memory, a.Threads, keyLength), nil
+}
+
+// Validate - check parameters limits.
+func (a Argon2id) Validate() error {
+	if a.Memory < 8*uint32(a.Threads) || a.Memory > MaxArgon2Memory ||
+		a.Time == 0 || a.Time > MaxArgon2Time ||
+		a.Threads == 0 || a.Threads > MaxArgon2Threads {
+		return ErrIncorrectAlgorithmParams
+	}
+
+	return nil
+}
+
+// Scrypt - memory-hard algorithm.
+// N - CPU/memory cost, power of two.
+type Scrypt struct {
+	N int
+	R int
+	P int
+}
+
+// ID - algorithm identifier.
+func (s Scrypt) ID() string {
+	return AlgorithmScrypt
+}
+
+// Params - algorithm parameters.
+func (s Scrypt) Params() Extension {
+	return Extension{
+		{Name: "n", Values: []string{strconv.Itoa(s.N)}},
+		{Name: "r", Values: []string{strconv.Itoa(s.R)}},
+		{Name: "p", Values: []string{strconv.Itoa(s.P)}},
+	}
+}
+
+// Sum - returns scrypt key of data.
+func (s Scrypt) Sum(data []byte) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key(data, salt, s.N, s.R, s.P, keyLength)
+	if err != nil {
+		return nil, ErrIncorrectAlgorithmParams
+	}
+
+	return key, nil
+}
+
+// Validate - check parameters limits.
+func (s Scrypt) Validate() error {
+	if s.N <= 1 || s.N > MaxScryptN || bits.OnesCount(uint(s.N)) != 1 ||
+		s.R <= 0 || s.R > MaxScryptR ||
+		s.P <= 0 || s.P > MaxScryptP {
+		return ErrIncorrectAlgorithmParams
+	}
+
+	return nil
+}
+
+// algorithmExtension - returns extension with algorithm id and parameters.
+// Format - alg=id;name=value;... , empty for default algorithm to keep header compatible.
+func algorithmExtension(algorithm Algorithm) Extension {
+	if algorithm.ID() == AlgorithmSHA256 {
+		return nil
+	}
+
+	return append(Extension{{Name: ExtensionAlgorithm, Values: []string{algorithm.ID()}}}, algorithm.Params()...)
+}
+
+// parseAlgorithm - returns algorithm from extension, default algorithm if extension has no algorithm field.
+func parseAlgorithm(ext Extension) (Algorithm, error) {
+	id, ok := ext.Get(ExtensionAlgorithm)
+	if !ok {
+		return SHA256{}, nil
+	}
+
+	params := make(map[string]string)
+	for _, field := range ext {
+		params[field.Name], _ = ext.Get(field.Name)
+	}
+
+	switch id {
+	case AlgorithmSHA256:
+		return SHA256{}, nil
+	case AlgorithmArgon2id:
+		return parseArgon2id(params)
+	case AlgorithmScrypt:
+		return parseScrypt(params)
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+}
+
+func parseArgon2id(params map[string]string) (Algorithm, error) {
+	memory, errM := strconv.ParseUint(params["m"], 10, 32)
+	iterations, errT := strconv.ParseUint(params["t"], 10, 32)
+	threads, errP := strconv.ParseUint(params["p"], 10, 8)
+
+	if errM != nil || errT != nil || errP != nil {
+		return nil, ErrIncorrectAlgorithmParams
+	}
+
+	algorithm := Argon2id{Memory: uint32(memory), Time: uint32(iterations), Threads: uint8(threads)}
+
+	return algorithm, algorithm.Validate()
+}
+
+func parseScrypt(params map[string]string) (Algorithm, error) {
+	n, errN := strconv.Atoi(params["n"])
+	r, errR := strconv.Atoi(params["r"])
+	p, errP := strconv.Atoi(params["p"])
+
+	if errN != nil || errR != nil || errP != nil {
+		return nil, ErrIncorrectAlgorithmParams
+	}
+
+	algorithm := Scrypt{N: n, R: r, P: p}
+
+	return algorithm, algorithm.Validate()
+}
@@ -0,0 +1,60 @@
+package hashcash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Algorithm(t *testing.T) {
+	t.Run("memory-hard compute and verify ok", func(t *testing.T) {
+		for _, algorithm := range []Algorithm{
+			Argon2id{Memory: 64, Time: 1, Threads: 1},
+			Scrypt{N: 16, R: 1, P: 1},
+		} {
+			original, err := NewWithAlgorithm(1, "resource", algorithm, time.Now())
+			require.NoError(t, err)
+			require.NoError(t, original.Compute(1000))
+
+			parsed, err := ParseHeader(string(original.Header()))
+			require.NoError(t, err)
+			require.Equal(t, original, parsed)
+			require.Equal(t, algorithm, parsed.Algorithm())
+
+			ok, err := parsed.IsSolved()
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("header format ok", func(t *testing.T) {
+		header := "1:1:20231102192537:resource:alg=argon2id;m=64;t=1;p=1:Cxphfw==:MA=="
+
+		hashcash, err := ParseHeader(header)
+		require.NoError(t, err)
+		require.Equal(t, Argon2id{Memory: 64, Time: 1, Threads: 1}, hashcash.Algorithm())
+		require.Equal(t, Header(header), hashcash.Header())
+
+		hashcash, err = ParseHeader("1:5:20231102192537:resource::Cxphfw==:MA==")
+		require.NoError(t, err)
+		require.Equal(t, SHA256{}, hashcash.Algorithm())
+	})
+
+	t.Run("header parse failed", func(t *testing.T) {
+		_, err := ParseHeader("1:1:20231102192537:resource:alg=md5:Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+		_, err = ParseHeader("1:1:20231102192537:resource:alg=argon2id;m=4294967295;t=1;p=1:Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrIncorrectAlgorithmParams)
+
+		_, err = ParseHeader("1:1:20231102192537:resource:alg=scrypt;n=15;r=1;p=1:Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrIncorrectAlgorithmParams)
+
+		_, err = ParseHeader("1:1:20231102192537:resource:alg=scrypt;n:Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrIncorrectAlgorithmParams)
+
+		_, err = ParseHeader("1:1:20231102192537:resource:alg=scrypt;=1:Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrIncorrectExtension)
+	})
+}
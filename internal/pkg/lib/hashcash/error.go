@@ -7,4 +7,11 @@ var (
 	ErrHashLengthLessThanZeroBits   = errors.New("hash length cannot be less than zero bits")
 	ErrZeroBitsMustBeMoreThanZero   = errors.New("zero bits must be more than zero")
 	ErrComputingMaxAttemptsExceeded = errors.New("max attempts to compute correct hash exceeded")
+	ErrUnknownAlgorithm             = errors.New("unknown algorithm")
+	ErrIncorrectAlgorithmParams     = errors.New("incorrect algorithm parameters")
+	ErrIncorrectExtension           = errors.New("incorrect extension format")
+	ErrResourceTooLong              = errors.New("resource too long")
+	ErrCounterTooLarge              = errors.New("counter too large")
+	ErrDateInFuture                 = errors.New("date in future")
+	ErrIncorrectRand                = errors.New("incorrect random field")
 )
@@ -0,0 +1,207 @@
+package hashcash
+
+import "strings"
+
+// Extension field names.
+const (
+	ExtensionAlgorithm = "alg"    // algorithm id, algorithm parameters are separate fields.
+	ExtensionScheme    = "scheme" // puzzle scheme id.
+	ExtensionExpiry    = "exp"    // puzzle expiration, unix time in seconds.
+	ExtensionKeyID     = "kid"    // id of key used to sign puzzle.
+	ExtensionSignature = "sig"    // puzzle signature.
+)
+
+const (
+	delimiterFields = ";"
+	delimiterName   = "="
+	delimiterValues = ","
+	escapeChar      = '%'
+	upperHex        = "0123456789ABCDEF"
+)
+
+// ExtensionField - extension field with name and optional values.
+// Values is nil for field without values, e.g. "name", and contains empty value for "name=".
+type ExtensionField struct {
+	Name   string
+	Values []string
+}
+
+// Extension - hashcash extension, list of fields in original order.
+// Format by hashcash v1 spec - name1[=value1[,value2...]];name2[=value1[,value2...]]...
+// Characters ':', ';', '=', ',', '%' and non-printable characters in names and values are escaped as %XX,
+// so extension never contains header delimiter.
+type Extension []ExtensionField
+
+// ParseExtension - parse extension.
+// Only canonical escaping is accepted, so parsed extension is serialized back verbatim.
+func ParseExtension(s string) (Extension, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(s, delimiterFields)
+	ext := make(Extension, 0, len(fields))
+
+	for _, field := range fields {
+		name, values, hasValues := strings.Cut(field, delimiterName)
+
+		parsed := ExtensionField{}
+
+		var ok bool
+		if parsed.Name, ok = unescape(name); !ok || parsed.Name == "" {
+			return nil, ErrIncorrectExtension
+		}
+
+		if hasValues {
+			for _, value := range strings.Split(values, delimiterValues) {
+				unescaped, ok := unescape(value)
+				if !ok {
+					return nil, ErrIncorrectExtension
+				}
+
+				parsed.Values = append(parsed.Values, unescaped)
+			}
+		}
+
+		ext = append(ext, parsed)
+	}
+
+	return ext, nil
+}
+
+// String - returns serialized extension.
+func (e Extension) String() string {
+	return string(e.appendTo(nil))
+}
+
+// appendTo - append serialized extension to buffer.
+func (e Extension) appendTo(b []byte) []byte {
+	for i, field := range e {
+		if i > 0 {
+			b = append(b, delimiterFields...)
+		}
+
+		b = appendEscaped(b, field.Name)
+
+		for j, value := range field.Values {
+			if j == 0 {
+				b = append(b, delimiterName...)
+			} else {
+				b = append(b, delimiterValues...)
+			}
+
+			b = appendEscaped(b, value)
+		}
+	}
+
+	return b
+}
+
+// Get - returns the first value of field.
+func (e Extension) Get(name string) (string, bool) {
+	for _, field := range e {
+		if field.Name == name {
+			if len(field.Values) == 0 {
+				return "", true
+			}
+
+			return field.Values[0], true
+		}
+	}
+
+	return "", false
+}
+
+// Set - returns extension with field values replaced or with new field appended.
+func (e Extension) Set(name string, values ...string) Extension {
+	ext := make(Extension, 0, len(e)+1)
+	found := false
+
+	for _, field := range e {
+		if field.Name == name {
+			field.Values = values
+			found = true
+		}
+
+		ext = append(ext, field)
+	}
+
+	if !found {
+		ext = append(ext, ExtensionField{Name: name, Values: values})
+	}
+
+	return ext
+}
+
+// Delete - returns extension without field.
+func (e Extension) Delete(name string) Extension {
+	var ext Extension
+
+	for _, field := range e {
+		if field.Name != name {
+			ext = append(ext, field)
+		}
+	}
+
+	return ext
+}
+
+func shouldEscape(c byte) bool {
+	switch c {
+	case ':', ';', '=', ',', escapeChar:
+		return true
+	default:
+		return c <= ' ' || c >= 0x7f
+	}
+}
+
+func appendEscaped(b []byte, s string) []byte {
+	for i := range len(s) {
+		if c := s[i]; shouldEscape(c) {
+			b = append(b, escapeChar, upperHex[c>>4], upperHex[c&0x0f])
+		} else {
+			b = append(b, c)
+		}
+	}
+
+	return b
+}
+
+// unescape - decode escaped string, returns false if escaping is incorrect or not canonical.
+func unescape(s string) (string, bool) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c != escapeChar {
+			if shouldEscape(c) {
+				return "", false
+			}
+
+			b.WriteByte(c)
+
+			continue
+		}
+
+		if i+2 >= len(s) {
+			return "", false
+		}
+
+		hi, lo := strings.IndexByte(upperHex, s[i+1]), strings.IndexByte(upperHex, s[i+2])
+		if hi < 0 || lo < 0 {
+			return "", false
+		}
+
+		decoded := byte(hi<<4 | lo)
+		if !shouldEscape(decoded) {
+			return "", false
+		}
+
+		b.WriteByte(decoded)
+
+		i += 2
+	}
+
+	return b.String(), true
+}
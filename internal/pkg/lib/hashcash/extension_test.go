@@ -0,0 +1,116 @@
+package hashcash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Extension(t *testing.T) {
+	t.Run("parse and serialize verbatim", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"name",
+			"name=",
+			"name=value",
+			"a=1,2,3;b;c=",
+			"url=http%3A//example.com/a%3Bb%3Dc%2Cd%25e",
+			"note=two%20words%0A",
+		} {
+			ext, err := ParseExtension(s)
+			require.NoError(t, err, s)
+			require.Equal(t, s, ext.String())
+		}
+	})
+
+	t.Run("fields ok", func(t *testing.T) {
+		ext, err := ParseExtension("a=1,2;b;url=x%3Ay%3Bz")
+		require.NoError(t, err)
+		require.Equal(t, Extension{
+			{Name: "a", Values: []string{"1", "2"}},
+			{Name: "b"},
+			{Name: "url", Values: []string{"x:y;z"}},
+		}, ext)
+
+		value, ok := ext.Get("a")
+		require.True(t, ok)
+		require.Equal(t, "1", value)
+
+		_, ok = ext.Get("c")
+		require.False(t, ok)
+
+		ext = ext.Set("a", "3").Set("c", "k=v").Delete("b")
+		require.Equal(t, "a=3;url=x%3Ay%3Bz;c=k%3Dv", ext.String())
+	})
+
+	t.Run("parse failed", func(t *testing.T) {
+		for _, s := range []string{
+			";",
+			"=value",
+			"a=b=c",
+			"a:b",
+			"a b",
+			"a=%3",
+			"a=%3a",
+			"a=%zz",
+			"a=%41",
+		} {
+			_, err := ParseExtension(s)
+			require.ErrorIs(t, err, ErrIncorrectExtension, s)
+		}
+	})
+
+	t.Run("header with escaped extension ok", func(t *testing.T) {
+		original, err := New(1, "res:ource", time.Now())
+		require.NoError(t, err)
+
+		original.SetExtension("note", "a:b;c=d,e")
+		original.SetExpiry(time.Now().Add(time.Minute))
+
+		parsed, err := ParseHeader(string(original.Header()))
+		require.NoError(t, err)
+		require.Equal(t, original, parsed)
+		require.True(t, parsed.EqualResource("res:ource"))
+
+		note, ok := parsed.Extension().Get("note")
+		require.True(t, ok)
+		require.Equal(t, "a:b;c=d,e", note)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		h, err := New(1, "resource", now.Add(500*time.Millisecond))
+		require.NoError(t, err)
+		require.Equal(t, now, h.Date())
+		require.True(t, h.IsActual(now, time.Minute))
+		require.True(t, h.IsActual(now.Add(time.Minute-time.Second), time.Minute))
+		require.False(t, h.IsActual(now.Add(time.Minute), time.Minute))
+
+		h.SetExpiry(now.Add(time.Second))
+		require.True(t, h.IsActual(now, time.Minute))
+		require.False(t, h.IsActual(now.Add(time.Second), time.Minute))
+
+		h.SetExtension(ExtensionExpiry, "soon")
+		require.False(t, h.IsActual(now, time.Minute))
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		signer := NewSigner("1", []byte("key"))
+
+		h, err := New(1, "resource", time.Now())
+		require.NoError(t, err)
+		require.False(t, signer.Verify(h))
+
+		signer.Sign(h)
+		require.True(t, signer.Verify(h))
+
+		parsed, err := ParseHeader(string(h.Header()))
+		require.NoError(t, err)
+		require.True(t, signer.Verify(parsed))
+
+		parsed.SetExtension(ExtensionScheme, "other")
+		require.False(t, signer.Verify(parsed))
+	})
+}
@@ -4,49 +4,74 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/binary"
-	"encoding/hex"
 	"fmt"
 	"math"
-	"math/big"
+	"math/bits"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// Hashcash fields limits, checked by Precheck before hashing.
+const (
+	MaxResourceLength = 256
+	MaxCounter        = math.MaxInt32
+	RandLength        = 4
+)
+
 const (
 	dateLayout = "20060102150405"
-	zeroBit    = '0'
+	hexBase    = 16
+
+	bitsPerHexDigit  = 4
+	bitsPerByte      = 8
+	headerBufferSize = 512
+	maxCounterLength = 20
 )
 
-// New - returns new hashcash.
-func New(bits int, resource string) (*Hashcash, error) {
-	randomNumber, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32))
-	if err != nil {
-		return nil, fmt.Errorf("get random error: %w", err)
-	}
+// New - returns new hashcash with default algorithm issued at now.
+func New(bits int, resource string, now time.Time) (*Hashcash, error) {
+	return NewWithAlgorithm(bits, resource, SHA256{}, now)
+}
 
+// NewWithAlgorithm - returns new hashcash computed with algorithm issued at now.
+// Date is stored in UTC with seconds precision as in header.
+func NewWithAlgorithm(bits int, resource string, algorithm Algorithm, now time.Time) (*Hashcash, error) {
 	if bits <= 0 {
 		return nil, ErrZeroBitsMustBeMoreThanZero
 	}
 
+	randomBytes := make([]byte, RandLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, fmt.Errorf("get random error: %w", err)
+	}
+
 	return &Hashcash{
-		bits:     bits,
-		date:     time.Now().UTC().Truncate(time.Second),
-		resource: resource,
-		rand:     randomNumber.Bytes(),
+		bits:      bits,
+		date:      now.UTC().Truncate(time.Second),
+		resource:  resource,
+		extension: algorithmExtension(algorithm),
+		rand:      randomBytes,
+		algorithm: algorithm,
 	}, nil
 }
 
+// ExpectedAttempts - returns expected number of hash computations to find solution with bits zero bits.
+// Every zero bit is a zero hex digit of hash, so probability of success is 16^-bits.
+func ExpectedAttempts(bits int) float64 {
+	return math.Pow(hexBase, float64(bits))
+}
+
 // Hashcash - hashcash structure.
 // Version 1.
 type Hashcash struct {
 	bits      int       // number of zero bits in hashed code.
 	date      time.Time // time that the message was sent.
 	resource  string    // resource data string (IP address,  email address, etc)
-	extension string    // extension, ignored in this version
+	extension Extension // extension fields, algorithm id and parameters, puzzle metadata.
 	rand      []byte    // random characters.
 	counter   int       // computing counter.
+	algorithm Algorithm // hash algorithm.
 }
 
 // Bits - returns number of zero bits.
@@ -54,6 +79,16 @@ func (h *Hashcash) Bits() int {
 	return h.bits
 }
 
+// Algorithm - returns hash algorithm.
+func (h *Hashcash) Algorithm() Algorithm {
+	return h.algorithm
+}
+
+// Date - returns date of hashcash issuing.
+func (h *Hashcash) Date() time.Time {
+	return h.date
+}
+
 // Counter - returns counter.
 func (h *Hashcash) Counter() int {
 	return h.counter
@@ -64,18 +99,81 @@ func (h *Hashcash) EqualResource(resource string) bool {
 	return h.resource == resource
 }
 
-// IsActual - check if hashcash expiration exceeded ttl.
-func (h *Hashcash) IsActual(ttl time.Duration) bool {
-	return h.date.Add(ttl).After(time.Now().UTC())
+// Extension - returns extension fields.
+func (h *Hashcash) Extension() Extension {
+	return h.extension
+}
+
+// SetExtension - set extension field values, field is appended if it doesn't exist.
+// Algorithm fields are reserved, they are set by algorithm.
+func (h *Hashcash) SetExtension(name string, values ...string) {
+	h.extension = h.extension.Set(name, values...)
+}
+
+// Expiry - returns expiration time from extension, false if hashcash has no expiration.
+func (h *Hashcash) Expiry() (time.Time, bool) {
+	value, ok := h.extension.Get(ExtensionExpiry)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	exp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(exp, 0).UTC(), true
+}
+
+// SetExpiry - set expiration time to extension.
+func (h *Hashcash) SetExpiry(exp time.Time) {
+	h.SetExtension(ExtensionExpiry, strconv.FormatInt(exp.Unix(), 10))
+}
+
+// IsActual - check if hashcash expiration exceeded ttl or expiration time from extension, now is current time.
+func (h *Hashcash) IsActual(now time.Time, ttl time.Duration) bool {
+	if _, ok := h.extension.Get(ExtensionExpiry); ok {
+		exp, ok := h.Expiry()
+		if !ok || !exp.After(now) {
+			return false
+		}
+	}
+
+	return h.date.Add(ttl).After(now)
+}
+
+// Precheck - cheap checks of hashcash fields before hashing, now is current time.
+func (h *Hashcash) Precheck(now time.Time) error {
+	switch {
+	case len(h.resource) > MaxResourceLength:
+		return ErrResourceTooLong
+	case h.counter < 0 || h.counter > MaxCounter:
+		return ErrCounterTooLarge
+	case h.date.After(now):
+		return ErrDateInFuture
+	case len(h.rand) != RandLength:
+		return ErrIncorrectRand
+	default:
+		return nil
+	}
 }
 
 // Compute - compute hash with enough zero bits in the beginning.
 // Increase counter if hash does't have enough zero bits in the beginning.
 func (h *Hashcash) Compute(maxAttempts int) error {
+	return h.ComputeWithProgress(maxAttempts, nil)
+}
+
+// ComputeWithProgress - compute hash and call progress with number of attempts before every attempt.
+func (h *Hashcash) ComputeWithProgress(maxAttempts int, progress func(attempts int)) error {
 	if maxAttempts > 0 {
 		h.counter = 0
 		for h.counter <= maxAttempts {
-			ok, err := h.Header().IsHashCorrect(h.bits)
+			if progress != nil {
+				progress(h.counter)
+			}
+
+			ok, err := h.IsSolved()
 			if err != nil {
 				return err
 			}
@@ -91,48 +189,83 @@ func (h *Hashcash) Compute(maxAttempts int) error {
 	return ErrComputingMaxAttemptsExceeded
 }
 
+// IsSolved - does hashcash hash contain zero bits enough.
+// Header for default algorithm is built in stack buffer and hashed once, so check allocates nothing.
+func (h *Hashcash) IsSolved() (bool, error) {
+	if _, ok := h.algorithm.(SHA256); ok {
+		var buf [headerBufferSize]byte
+
+		return isSHA256Correct(h.appendHeader(buf[:0]), h.bits)
+	}
+
+	return h.Header().IsHashCorrectWith(h.algorithm, h.bits)
+}
+
 // Key - returns string presentation of hashcash without counter.
 // Key is using to match original hashcash with solved hashcash.
 func (h *Hashcash) Key() string {
-	return fmt.Sprintf("%d:%d:%s:%d", h.bits, h.date.Unix(), h.resource, binary.BigEndian.Uint32(h.rand))
+	return fmt.Sprintf("%d:%d:%s:%s:%s",
+		h.bits, h.date.Unix(), h.resource, h.extension.String(), base64.StdEncoding.EncodeToString(h.rand))
+}
+
+// IssueKey - returns Key without bits, so hashcash with claimed bits changed is still matched with original one.
+func (h *Hashcash) IssueKey() string {
+	return fmt.Sprintf("%d:%s:%s:%s",
+		h.date.Unix(), h.resource, h.extension.String(), base64.StdEncoding.EncodeToString(h.rand))
 }
 
 // Header - returns string presentation of hashcash to share it.
 func (h *Hashcash) Header() Header {
-	return Header(fmt.Sprintf("1:%d:%s:%s:%s:%s:%s",
-		h.bits,
-		h.date.Format(dateLayout),
-		h.resource,
-		h.extension,
-		base64.StdEncoding.EncodeToString(h.rand),
-		base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(h.counter))),
-	))
+	return Header(h.appendHeader(nil))
+}
+
+// appendHeader - append header to buffer.
+func (h *Hashcash) appendHeader(b []byte) []byte {
+	var counter [maxCounterLength]byte
+
+	b = append(b, "1:"...)
+	b = strconv.AppendInt(b, int64(h.bits), 10)
+	b = append(b, ':')
+	b = h.date.AppendFormat(b, dateLayout)
+	b = append(b, ':')
+	b = append(b, h.resource...)
+	b = append(b, ':')
+	b = h.extension.appendTo(b)
+	b = append(b, ':')
+	b = base64.StdEncoding.AppendEncode(b, h.rand)
+	b = append(b, ':')
+	b = base64.StdEncoding.AppendEncode(b, strconv.AppendInt(counter[:0], int64(h.counter), 10))
+
+	return b
 }
 
 // ParseHeader - parse hashcah from header.
+// Resource could contain ':', extension is escaped and doesn't, so the last three fields are taken from the end.
 func ParseHeader(header string) (*Hashcash, error) {
 	var (
-		hashcash = &Hashcash{}
-		err      error
-		parts    = strings.Split(header, ":")
-		maxParts = 7
+		hashcash  = &Hashcash{}
+		err       error
+		headParts = 4
+		tailParts = 3
 	)
 
-	if len(parts) < maxParts {
+	parts := strings.SplitN(header, ":", headParts)
+	if len(parts) < headParts {
 		return nil, ErrIncorrectHeaderFormat
 	}
 
-	if len(parts) > maxParts {
-		for i := range make([]struct{}, len(parts)-maxParts) {
-			parts[3] += ":" + parts[3+i+1]
+	for range tailParts {
+		i := strings.LastIndex(parts[3], ":")
+		if i < 0 {
+			return nil, ErrIncorrectHeaderFormat
 		}
 
-		parts[4] = parts[len(parts)-3]
-		parts[5] = parts[len(parts)-2]
-		parts[6] = parts[len(parts)-1]
-		parts = parts[:7]
+		parts = append(parts, parts[3][i+1:])
+		parts[3] = parts[3][:i]
 	}
 
+	parts[4], parts[6] = parts[6], parts[4]
+
 	if parts[0] != "1" {
 		return nil, ErrIncorrectHeaderFormat
 	}
@@ -148,7 +281,15 @@ func ParseHeader(header string) (*Hashcash, error) {
 	}
 
 	hashcash.resource = parts[3]
-	hashcash.extension = parts[4]
+	hashcash.extension, err = ParseExtension(parts[4])
+	if err != nil {
+		return nil, err
+	}
+
+	hashcash.algorithm, err = parseAlgorithm(hashcash.extension)
+	if err != nil {
+		return nil, err
+	}
 
 	hashcash.rand, err = base64.StdEncoding.DecodeString(parts[5])
 	if err != nil {
@@ -172,39 +313,62 @@ func ParseHeader(header string) (*Hashcash, error) {
 // Format - 1:bits:date:resource:externsion:rand:counter.
 type Header string
 
-// IsHashCorrect - does header hash constain zero bits enough.
+// IsHashCorrect - does header sha256 hash constain zero bits enough.
 func (header Header) IsHashCorrect(bits int) (ok bool, err error) {
+	return header.IsHashCorrectWith(SHA256{}, bits)
+}
+
+// IsHashCorrectWith - does header hash computed with algorithm constain zero bits enough.
+// Every zero bit is a zero hex digit of hash, i.e. 4 leading zero bits of digest.
+func (header Header) IsHashCorrectWith(algorithm Algorithm, bits int) (ok bool, err error) {
+	if _, isSHA256 := algorithm.(SHA256); isSHA256 && len(header) <= headerBufferSize {
+		var buf [headerBufferSize]byte
+
+		return isSHA256Correct(append(buf[:0], header...), bits)
+	}
+
 	if bits <= 0 {
 		return false, ErrZeroBitsMustBeMoreThanZero
 	}
 
-	hash, err := header.sha256()
+	sum, err := algorithm.Sum([]byte(header))
 	if err != nil {
 		return ok, err
 	}
 
-	if len(hash) < bits {
-		return false, ErrHashLengthLessThanZeroBits
+	return hasZeroDigits(sum, bits)
+}
+
+// isSHA256Correct - does sha256 hash of data contain zero bits enough.
+// Digest is kept on stack, data must not escape to keep check allocation-free.
+func isSHA256Correct(data []byte, bits int) (bool, error) {
+	if bits <= 0 {
+		return false, ErrZeroBitsMustBeMoreThanZero
 	}
 
-	ok = true
+	sum := sha256.Sum256(data)
 
-	for _, s := range hash[:bits] {
-		if s != zeroBit {
-			ok = false
+	return hasZeroDigits(sum[:], bits)
+}
 
-			break
-		}
+// hasZeroDigits - does digest start with digits zero hex digits.
+func hasZeroDigits(sum []byte, digits int) (bool, error) {
+	zeroBits := digits * bitsPerHexDigit
+	if zeroBits > len(sum)*bitsPerByte {
+		return false, ErrHashLengthLessThanZeroBits
 	}
 
-	return
-}
+	for _, b := range sum {
+		if zeroBits < bitsPerByte {
+			return bits.LeadingZeros8(b) >= zeroBits, nil
+		}
+
+		if b != 0 {
+			return false, nil
+		}
 
-func (header Header) sha256() (hash string, err error) {
-	hasher := sha256.New()
-	if _, err = hasher.Write([]byte(header)); err != nil {
-		return
+		zeroBits -= bitsPerByte
 	}
 
-	return hex.EncodeToString(hasher.Sum(nil)), nil
+	return true, nil
 }
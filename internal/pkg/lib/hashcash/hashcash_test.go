@@ -1,14 +1,22 @@
 package hashcash
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand/v2"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
 func Test_New(t *testing.T) {
 	t.Run("new and parse ok", func(t *testing.T) {
-		original, err := New(20, ":reso:u:r:ce:")
+		original, err := New(20, ":reso:u:r:ce:", time.Now())
 		require.NoError(t, err)
 
 		parsed, err := ParseHeader(string(original.Header()))
@@ -18,6 +26,13 @@ func Test_New(t *testing.T) {
 		parsed.counter++
 		require.Equal(t, original.Key(), parsed.Key())
 	})
+
+	t.Run("key of short rand ok", func(t *testing.T) {
+		parsed, err := ParseHeader("1:5:20231102192537:resource::AQ==:MA==")
+		require.NoError(t, err)
+		require.Equal(t, "5:1698953137:resource::AQ==", parsed.Key())
+		require.Equal(t, "1698953137:resource::AQ==", parsed.IssueKey())
+	})
 }
 
 func Test_Compute(t *testing.T) {
@@ -42,3 +57,204 @@ func Test_Compute(t *testing.T) {
 		require.EqualError(t, ErrComputingMaxAttemptsExceeded, err.Error())
 	})
 }
+
+func Test_Precheck(t *testing.T) {
+	now := time.Date(2023, 11, 2, 19, 25, 37, 0, time.UTC)
+
+	for _, tc := range []struct {
+		header string
+		err    error
+	}{
+		{header: "1:5:20231102192537:resource::Cxphfw==:MA==", err: nil},
+		{header: "1:5:20231102192537:" + strings.Repeat("a", MaxResourceLength+1) + "::Cxphfw==:MA==", err: ErrResourceTooLong},
+		{header: "1:5:20231102192537:resource::Cxphfw==:LTE=", err: ErrCounterTooLarge},
+		{header: "1:5:20231102192537:resource::Cxphfw==:MjE0NzQ4MzY0OA==", err: ErrCounterTooLarge},
+		{header: "1:5:20231102192538:resource::Cxphfw==:MA==", err: ErrDateInFuture},
+		{header: "1:5:20231102192537:resource::AQ==:MA==", err: ErrIncorrectRand},
+		{header: "1:5:20231102192537:resource::AQIDBAU=:MA==", err: ErrIncorrectRand},
+	} {
+		h, err := ParseHeader(tc.header)
+		require.NoError(t, err)
+		require.ErrorIs(t, h.Precheck(now), tc.err, tc.header)
+	}
+}
+
+func Test_IsSolved(t *testing.T) {
+	t.Run("zero hex digits checked on digest", func(t *testing.T) {
+		for _, tc := range []struct {
+			sum    []byte
+			digits int
+			ok     bool
+		}{
+			{sum: []byte{0x00, 0x00, 0x0f}, digits: 4, ok: true},
+			{sum: []byte{0x00, 0x00, 0x0f}, digits: 5, ok: true},
+			{sum: []byte{0x00, 0x00, 0x0f}, digits: 6, ok: false},
+			{sum: []byte{0x00, 0x10, 0x00}, digits: 3, ok: false},
+			{sum: []byte{0x00, 0x10, 0x00}, digits: 2, ok: true},
+		} {
+			ok, err := hasZeroDigits(tc.sum, tc.digits)
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, ok, tc)
+		}
+
+		_, err := hasZeroDigits([]byte{0x00}, 3)
+		require.ErrorIs(t, err, ErrHashLengthLessThanZeroBits)
+	})
+
+	t.Run("same result as hex check", func(t *testing.T) {
+		hashcash, err := ParseHeader("1:5:20231102192537:resource::Cxphfw==:MA==")
+		require.NoError(t, err)
+
+		// low bits, so both solved and unsolved cases are met, wide search is left to FuzzHeaderRoundTrip.
+		for range 5000 {
+			hashcash.bits = 1 + mathrand.IntN(3)
+			hashcash.counter = mathrand.IntN(MaxCounter)
+
+			ok, err := hashcash.IsSolved()
+			require.NoError(t, err)
+			require.Equal(t, isHashCorrectHex(hashcash.Header(), hashcash.bits), ok, hashcash.Header())
+		}
+	})
+
+	t.Run("no allocations", func(t *testing.T) {
+		hashcash, err := New(5, "127.0.0.1:1234", time.Now())
+		require.NoError(t, err)
+
+		hashcash.SetExtension(ExtensionScheme, "hashcash")
+		hashcash.counter = 123456
+
+		header := hashcash.Header()
+
+		require.Zero(t, testing.AllocsPerRun(100, func() { _, _ = hashcash.IsSolved() }))
+		require.Zero(t, testing.AllocsPerRun(100, func() { _, _ = header.IsHashCorrect(5) }))
+	})
+}
+
+// isHashCorrectHex - previous implementation, hex-encodes digest and scans characters.
+func isHashCorrectHex(header Header, bits int) bool {
+	sum := sha256.Sum256([]byte(header))
+	hash := hex.EncodeToString(sum[:])
+
+	return strings.Count(hash[:bits], "0") == bits
+}
+
+func Benchmark_Verify(b *testing.B) {
+	hashcash, err := New(5, "127.0.0.1:1234", time.Now())
+	require.NoError(b, err)
+
+	hashcash.SetExtension(ExtensionScheme, "hashcash")
+	hashcash.counter = 123456
+
+	b.Run("hex", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			isHashCorrectHex(Header(fmt.Sprintf("1:%d:%s:%s:%s:%s:%s",
+				hashcash.bits,
+				hashcash.date.Format(dateLayout),
+				hashcash.resource,
+				hashcash.extension.String(),
+				base64.StdEncoding.EncodeToString(hashcash.rand),
+				base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(hashcash.counter))),
+			)), hashcash.bits)
+		}
+	})
+
+	b.Run("digest", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			_, _ = hashcash.IsSolved()
+		}
+	})
+}
+
+// FuzzParseHeader - parsed header is serialized and parsed back to the same hashcash,
+// serialized header is canonical and keeps resources with ":".
+func FuzzParseHeader(f *testing.F) {
+	for _, seed := range []string{
+		"1:5:20231102192537:resource::Cxphfw==:MA==",
+		"1:20:20231102192537::reso:u:r:ce::Cxphfw==:NDI=",
+		"1:2:20231102192537:resource:alg=argon2id;m=65536;t=1;p=1:Cxphfw==:MA==",
+		"1:2:20231102192537:resource:alg=scrypt;n=32768;r=8;p=1:Cxphfw==:MA==",
+		"1:5:20231102192537:resource:scheme=hashcash;exp=1698953197;kid=1;sig=abc:Cxphfw==:MA==",
+		"1:5:20231102192537:resource:a=%3A%3B,b;c:Cxphfw==:MA==",
+		"1:+5:20231102192537:resource::Cxphfw==:KzE=",
+		"1:-5:20231102192537:resource::Cxphfw==:LTE=",
+		"1:5:20231102192537:resource:::",
+		"1:5:20231102192537:resource",
+		"2:5:20231102192537:resource::Cxphfw==:MA==",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, header string) {
+		h, err := ParseHeader(header)
+		if err != nil {
+			require.Nil(t, h)
+
+			return
+		}
+
+		serialized := string(h.Header())
+
+		parsed, err := ParseHeader(serialized)
+		require.NoError(t, err, serialized)
+		require.Equal(t, h, parsed)
+		require.Equal(t, serialized, string(parsed.Header()), "serialized header isn't canonical")
+		require.Equal(t, h.Key(), parsed.Key())
+
+		require.True(t, strings.HasPrefix(serialized, "1:"))
+		require.Equal(t, h.resource, parsed.resource)
+	})
+}
+
+// FuzzHeaderRoundTrip - hashcash built from any fields is parsed back from its header.
+func FuzzHeaderRoundTrip(f *testing.F) {
+	f.Add(5, int64(1698953137), "resource", "scheme=hashcash;exp=1698953197", []byte{1, 2, 3, 4}, 42)
+	f.Add(-1, int64(0), ":reso:u:r:ce:", "", []byte{}, -7)
+	f.Add(20, int64(253402300799), "a:b", "alg=sha256", []byte{0xff}, 0)
+
+	f.Fuzz(func(t *testing.T, bits int, unix int64, resource, extension string, rand []byte, counter int) {
+		date := time.Unix(unix, 0).UTC()
+		if date.Year() < 0 || date.Year() > 9999 || strings.ContainsRune(resource, '\n') {
+			t.Skip("date or resource can't be serialized")
+		}
+
+		ext, err := ParseExtension(extension)
+		if err != nil {
+			t.Skip("incorrect extension")
+		}
+
+		algorithm, err := parseAlgorithm(ext)
+		if err != nil {
+			t.Skip("incorrect algorithm")
+		}
+
+		h := &Hashcash{
+			bits:      bits,
+			date:      date,
+			resource:  resource,
+			extension: ext,
+			rand:      rand,
+			counter:   counter,
+			algorithm: algorithm,
+		}
+
+		parsed, err := ParseHeader(string(h.Header()))
+		require.NoError(t, err, h.Header())
+		require.Equal(t, h.bits, parsed.bits)
+		require.True(t, h.date.Equal(parsed.date))
+		require.Equal(t, h.resource, parsed.resource)
+		require.Equal(t, h.extension.String(), parsed.extension.String())
+		require.Equal(t, h.rand, parsed.rand)
+		require.Equal(t, h.counter, parsed.counter)
+		require.Equal(t, h.Key(), parsed.Key())
+
+		if _, ok := algorithm.(SHA256); ok && bits > 0 && bits <= sha256.Size*2 {
+			solved, err := parsed.IsSolved()
+			require.NoError(t, err)
+			require.Equal(t, isHashCorrectHex(parsed.Header(), bits), solved, parsed.Header())
+		}
+	})
+}
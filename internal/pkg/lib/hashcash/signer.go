@@ -0,0 +1,54 @@
+package hashcash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// NewSigner - returns signer with key identified by keyID.
+func NewSigner(keyID string, key []byte) *Signer {
+	return &Signer{
+		keyID: keyID,
+		key:   key,
+	}
+}
+
+// Signer - signs hashcash with HMAC-SHA256.
+// Signature covers hashcash key without signature field, so any change of issued fields breaks it.
+type Signer struct {
+	keyID string
+	key   []byte
+}
+
+// KeyID - returns key id.
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign - set key id and signature extension fields.
+func (s *Signer) Sign(h *Hashcash) {
+	h.SetExtension(ExtensionKeyID, s.keyID)
+	h.SetExtension(ExtensionSignature, s.signature(h))
+}
+
+// Verify - check key id and signature extension fields.
+func (s *Signer) Verify(h *Hashcash) bool {
+	if keyID, ok := h.extension.Get(ExtensionKeyID); !ok || keyID != s.keyID {
+		return false
+	}
+
+	signature, ok := h.extension.Get(ExtensionSignature)
+
+	return ok && hmac.Equal([]byte(signature), []byte(s.signature(h)))
+}
+
+func (s *Signer) signature(h *Hashcash) string {
+	unsigned := *h
+	unsigned.extension = h.extension.Delete(ExtensionSignature)
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(unsigned.Key()))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,231 @@
+package hashcash
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // sha1 is required by hashcash v1 spec.
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Standard hashcash v1 date layouts - YYMMDD[hhmm[ss]].
+const (
+	StampDateLayoutDay    = "060102"
+	StampDateLayoutMinute = "0601021504"
+	StampDateLayoutSecond = "060102150405"
+)
+
+const (
+	stampVersion     = "1"
+	stampRandLength  = 16
+	stampMaxBits     = sha1.Size * 8
+	stampAlphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	stampHeadParts   = 4
+	stampTailParts   = 3
+	stampDelimiter   = ":"
+	stampAlphabetLen = len(stampAlphabet)
+)
+
+// NewStamp - returns new standard hashcash v1 stamp with random string and empty counter.
+// Date is formatted with layout, one of StampDateLayout*.
+func NewStamp(bits int, resource string, date time.Time, layout string) (*Stamp, error) {
+	if bits <= 0 || bits > stampMaxBits {
+		return nil, ErrZeroBitsMustBeMoreThanZero
+	}
+
+	if !isStampDateLayout(layout) {
+		return nil, ErrIncorrectHeaderFormat
+	}
+
+	if strings.Contains(resource, stampDelimiter) {
+		return nil, ErrIncorrectHeaderFormat
+	}
+
+	randomBytes := make([]byte, stampRandLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, fmt.Errorf("get random error: %w", err)
+	}
+
+	for i, b := range randomBytes {
+		randomBytes[i] = stampAlphabet[int(b)%stampAlphabetLen]
+	}
+
+	return &Stamp{
+		bits:     bits,
+		date:     date.UTC().Format(layout),
+		resource: resource,
+		rand:     string(randomBytes),
+	}, nil
+}
+
+// Stamp - standard hashcash v1 stamp, compatible with reference hashcash tool.
+// Format - 1:bits:YYMMDD[hhmm[ss]]:resource:extension:rand:counter.
+// Stamp is valid if SHA-1 hash of the whole stamp has at least bits leading zero bits.
+// All fields are kept as is, so parsed stamp is serialized back byte-for-byte.
+type Stamp struct {
+	bits      int
+	date      string
+	resource  string
+	extension string
+	rand      string
+	counter   string
+}
+
+// ParseStamp - parse standard hashcash v1 stamp.
+func ParseStamp(stamp string) (*Stamp, error) {
+	parts := strings.SplitN(stamp, stampDelimiter, stampHeadParts)
+	if len(parts) < stampHeadParts || parts[0] != stampVersion {
+		return nil, ErrIncorrectHeaderFormat
+	}
+
+	// resource doesn't contain delimiter by spec, but tail is taken from the end to be tolerant.
+	for range stampTailParts {
+		i := strings.LastIndex(parts[3], stampDelimiter)
+		if i < 0 {
+			return nil, ErrIncorrectHeaderFormat
+		}
+
+		parts = append(parts, parts[3][i+1:])
+		parts[3] = parts[3][:i]
+	}
+
+	s := &Stamp{
+		date:      parts[2],
+		resource:  parts[3],
+		extension: parts[6],
+		rand:      parts[5],
+		counter:   parts[4],
+	}
+
+	var err error
+
+	s.bits, err = strconv.Atoi(parts[1])
+	if err != nil || s.bits < 0 || strconv.Itoa(s.bits) != parts[1] {
+		return nil, ErrIncorrectHeaderFormat
+	}
+
+	if _, err = parseStampDate(s.date); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// String - returns stamp.
+func (s *Stamp) String() string {
+	return strings.Join([]string{
+		stampVersion, strconv.Itoa(s.bits), s.date, s.resource, s.extension, s.rand, s.counter,
+	}, stampDelimiter)
+}
+
+// Bits - returns claimed number of zero bits.
+func (s *Stamp) Bits() int {
+	return s.bits
+}
+
+// Date - returns stamp date, UTC.
+func (s *Stamp) Date() time.Time {
+	date, _ := parseStampDate(s.date)
+
+	return date
+}
+
+// Resource - returns resource.
+func (s *Stamp) Resource() string {
+	return s.resource
+}
+
+// Extension - returns parsed extension.
+func (s *Stamp) Extension() (Extension, error) {
+	return ParseExtension(s.extension)
+}
+
+// Key - returns stamp without counter to detect double spending.
+func (s *Stamp) Key() string {
+	return strings.Join([]string{
+		stampVersion, strconv.Itoa(s.bits), s.date, s.resource, s.extension, s.rand,
+	}, stampDelimiter)
+}
+
+// ZeroBits - returns number of leading zero bits of stamp SHA-1 hash.
+func (s *Stamp) ZeroBits() int {
+	return leadingZeroBits(sha1.Sum([]byte(s.String()))) //nolint:gosec // sha1 is required by hashcash v1 spec.
+}
+
+// IsValid - check if stamp hash has at least claimed number of zero bits.
+func (s *Stamp) IsValid() bool {
+	return s.bits > 0 && s.ZeroBits() >= s.bits
+}
+
+// Mint - search counter to get hash with claimed number of zero bits.
+// Counter is encoded with base64 alphabet like in reference tool.
+func (s *Stamp) Mint(maxAttempts int) error {
+	prefix := []byte(s.Key() + stampDelimiter)
+
+	for attempt := range maxAttempts {
+		counter := encodeStampCounter(attempt)
+
+		if leadingZeroBits(sha1.Sum(append(prefix, counter...))) >= s.bits { //nolint:gosec // sha1 is required by spec.
+			s.counter = counter
+
+			return nil
+		}
+	}
+
+	return ErrComputingMaxAttemptsExceeded
+}
+
+func leadingZeroBits(sum [sha1.Size]byte) int {
+	n := 0
+
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+
+		n += 8
+	}
+
+	return n
+}
+
+func encodeStampCounter(n int) string {
+	if n == 0 {
+		return stampAlphabet[:1]
+	}
+
+	var encoded []byte
+	for ; n > 0; n /= stampAlphabetLen {
+		encoded = append([]byte{stampAlphabet[n%stampAlphabetLen]}, encoded...)
+	}
+
+	return string(encoded)
+}
+
+func isStampDateLayout(layout string) bool {
+	return layout == StampDateLayoutDay || layout == StampDateLayoutMinute || layout == StampDateLayoutSecond
+}
+
+func parseStampDate(date string) (time.Time, error) {
+	var layout string
+
+	switch len(date) {
+	case len(StampDateLayoutDay):
+		layout = StampDateLayoutDay
+	case len(StampDateLayoutMinute):
+		layout = StampDateLayoutMinute
+	case len(StampDateLayoutSecond):
+		layout = StampDateLayoutSecond
+	default:
+		return time.Time{}, ErrIncorrectHeaderFormat
+	}
+
+	parsed, err := time.ParseInLocation(layout, date, time.UTC)
+	if err != nil {
+		return time.Time{}, ErrIncorrectHeaderFormat
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,111 @@
+package hashcash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Stamp(t *testing.T) {
+	t.Run("reference tool vectors ok", func(t *testing.T) {
+		for _, vector := range []struct {
+			stamp    string
+			date     time.Time
+			resource string
+		}{
+			{
+				stamp:    "1:20:040806:foo::65f460d0726f420d:13a6b8",
+				date:     time.Date(2004, 8, 6, 0, 0, 0, 0, time.UTC),
+				resource: "foo",
+			},
+			{
+				stamp:    "1:20:060408:adam@cypherspace.org::1QTjaYd7niiQA/sc:ePa",
+				date:     time.Date(2006, 4, 8, 0, 0, 0, 0, time.UTC),
+				resource: "adam@cypherspace.org",
+			},
+			{
+				stamp:    "1:20:1303030600:adam@cypherspace.org::McMybZIhxKXu57jd:ckvi",
+				date:     time.Date(2013, 3, 3, 6, 0, 0, 0, time.UTC),
+				resource: "adam@cypherspace.org",
+			},
+		} {
+			stamp, err := ParseStamp(vector.stamp)
+			require.NoError(t, err)
+			require.Equal(t, vector.stamp, stamp.String())
+			require.Equal(t, 20, stamp.Bits())
+			require.Equal(t, vector.date, stamp.Date())
+			require.Equal(t, vector.resource, stamp.Resource())
+			require.GreaterOrEqual(t, stamp.ZeroBits(), 20)
+			require.True(t, stamp.IsValid())
+		}
+	})
+
+	t.Run("invalid stamp", func(t *testing.T) {
+		stamp, err := ParseStamp("1:20:1303030600:anni@cypherspace.org::McMybZIhxKXu57jd:ckvi")
+		require.NoError(t, err)
+		require.Equal(t, 3, stamp.ZeroBits())
+		require.False(t, stamp.IsValid())
+	})
+
+	t.Run("mint and verify ok", func(t *testing.T) {
+		date := time.Date(2023, 11, 2, 19, 25, 37, 0, time.UTC)
+
+		for _, layout := range []string{StampDateLayoutDay, StampDateLayoutMinute, StampDateLayoutSecond} {
+			minted, err := NewStamp(12, "user@example.com", date, layout)
+			require.NoError(t, err)
+			require.NoError(t, minted.Mint(1000000))
+			require.True(t, minted.IsValid())
+
+			parsed, err := ParseStamp(minted.String())
+			require.NoError(t, err)
+			require.Equal(t, minted, parsed)
+			require.Equal(t, date.Truncate(map[string]time.Duration{
+				StampDateLayoutDay:    24 * time.Hour,
+				StampDateLayoutMinute: time.Minute,
+				StampDateLayoutSecond: time.Second,
+			}[layout]), parsed.Date())
+		}
+	})
+
+	t.Run("extension ok", func(t *testing.T) {
+		stamp, err := ParseStamp("1:10:231102:foo:note=a%3Ab;x:abc:1")
+		require.NoError(t, err)
+
+		ext, err := stamp.Extension()
+		require.NoError(t, err)
+		require.Equal(t, Extension{{Name: "note", Values: []string{"a:b"}}, {Name: "x"}}, ext)
+	})
+
+	t.Run("parse failed", func(t *testing.T) {
+		for _, stamp := range []string{
+			"",
+			"0:20:040806:foo::65f460d0726f420d:13a6b8",
+			"1:x:040806:foo::65f460d0726f420d:13a6b8",
+			"1:020:040806:foo::65f460d0726f420d:13a6b8",
+			"1:20:20040806:foo::65f460d0726f420d:13a6b8",
+			"1:20:041306:foo::65f460d0726f420d:13a6b8",
+			"1:20:040806:foo:65f460d0726f420d:13a6b8",
+		} {
+			_, err := ParseStamp(stamp)
+			require.ErrorIs(t, err, ErrIncorrectHeaderFormat, stamp)
+		}
+	})
+
+	t.Run("new failed", func(t *testing.T) {
+		_, err := NewStamp(0, "foo", time.Now(), StampDateLayoutDay)
+		require.ErrorIs(t, err, ErrZeroBitsMustBeMoreThanZero)
+
+		_, err = NewStamp(10, "foo", time.Now(), dateLayout)
+		require.ErrorIs(t, err, ErrIncorrectHeaderFormat)
+
+		_, err = NewStamp(10, "foo:bar", time.Now(), StampDateLayoutDay)
+		require.ErrorIs(t, err, ErrIncorrectHeaderFormat)
+	})
+
+	t.Run("counter encoding", func(t *testing.T) {
+		require.Equal(t, "A", encodeStampCounter(0))
+		require.Equal(t, "/", encodeStampCounter(63))
+		require.Equal(t, "BA", encodeStampCounter(64))
+	})
+}
@@ -2,4 +2,11 @@ package message
 
 import "errors"
 
-var ErrIncorrectMessageFormat = errors.New("incorrect message format")
+var (
+	ErrIncorrectMessageFormat = errors.New("incorrect message format")
+	ErrIncorrectHelloFormat   = errors.New("incorrect hello format")
+	ErrUnsupportedCommand     = errors.New("unsupported command")
+	ErrIncorrectSpec          = errors.New("command spec must have code, name and direction")
+	ErrCommandRegistered      = errors.New("command already registered")
+	ErrWrongDirection         = errors.New("command isn't allowed in this direction")
+)
@@ -0,0 +1,103 @@
+package message
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// ProtocolVersion1 - the first protocol version, messages are "command:payload\n" text lines.
+	ProtocolVersion1 = "1"
+
+	// CodecText - text codec, puzzles and resources are sent as is.
+	CodecText = "text"
+)
+
+const (
+	// DelimiterHelloFields - sign to divide fields in hello payload.
+	DelimiterHelloFields = ";"
+
+	// DelimiterHelloField - sign to divide field name and values in hello payload.
+	DelimiterHelloField = "="
+
+	// DelimiterHelloValues - sign to divide values of hello field.
+	DelimiterHelloValues = ","
+)
+
+// Hello fields.
+const (
+	HelloVersions = "versions"
+	HelloSchemes  = "schemes"
+	HelloCodecs   = "codecs"
+)
+
+// Versions - supported protocol versions in preference order.
+func Versions() []string {
+	return []string{ProtocolVersion1}
+}
+
+// Codecs - supported codecs in preference order.
+func Codecs() []string {
+	return []string{CodecText}
+}
+
+// Hello - handshake payload, lists of protocol versions, puzzle schemes and codecs in preference order.
+// Client sends all supported values in Hello command, server answers with picked ones in Welcome command.
+// Format - "versions=1;schemes=timelock,hashcash;codecs=text", unknown fields are ignored.
+type Hello struct {
+	Versions []string
+	Schemes  []string
+	Codecs   []string
+}
+
+// ParseHello - parse hello payload.
+func ParseHello(payload string) (Hello, error) {
+	var hello Hello
+
+	for _, field := range strings.Split(payload, DelimiterHelloFields) {
+		name, list, ok := strings.Cut(field, DelimiterHelloField)
+		if !ok || name == "" {
+			return Hello{}, ErrIncorrectHelloFormat
+		}
+
+		var values []string
+		if list != "" {
+			values = strings.Split(list, DelimiterHelloValues)
+		}
+
+		if slices.Contains(values, "") {
+			return Hello{}, ErrIncorrectHelloFormat
+		}
+
+		switch name {
+		case HelloVersions:
+			hello.Versions = values
+		case HelloSchemes:
+			hello.Schemes = values
+		case HelloCodecs:
+			hello.Codecs = values
+		}
+	}
+
+	return hello, nil
+}
+
+// String - returns hello payload.
+func (h Hello) String() string {
+	return strings.Join([]string{
+		HelloVersions + DelimiterHelloField + strings.Join(h.Versions, DelimiterHelloValues),
+		HelloSchemes + DelimiterHelloField + strings.Join(h.Schemes, DelimiterHelloValues),
+		HelloCodecs + DelimiterHelloField + strings.Join(h.Codecs, DelimiterHelloValues),
+	}, DelimiterHelloFields)
+}
+
+// Negotiate - returns the most preferred value supported by peer.
+func Negotiate(preferred, peer []string) (string, bool) {
+	for _, value := range preferred {
+		if slices.Contains(peer, value) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
@@ -2,11 +2,12 @@ package message
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // Command - command type.
-type Command int8
+type Command int16
 
 const (
 	// CommandError - using when something went wrong by client or server.
@@ -23,6 +24,12 @@ const (
 
 	// CommandResponseResource - using when server sends resource to client.
 	CommandResponseResource
+
+	// CommandHello - using when client starts optional handshake with supported versions, schemes and codecs.
+	CommandHello
+
+	// CommandWelcome - using when server answers to handshake with picked version, scheme and codec.
+	CommandWelcome
 )
 
 const (
@@ -33,39 +40,39 @@ const (
 	DelimiterCommand = ':'
 )
 
-// string has "command:payload" format where command could be 0-4.
+// MaxCommand - max command code, codes have up to 4 digits.
+const MaxCommand Command = 9999
+
+// builtin - registry with built-in commands.
+var builtin = DefaultRegistry() //nolint:gochecknoglobals // read only.
+
+// ParseMessage - parse message of built-in command.
+// string has "command:payload" format where command is a decimal code without leading zeros.
 func ParseMessage(msg string) (Message, error) {
-	var (
-		message          Message
-		maxMessageLength = 2
-	)
+	return builtin.Parse(msg)
+}
 
+// parse - parse message of any command.
+func parse(msg string) (Message, error) {
 	msg = strings.TrimSpace(msg)
 
-	if len(msg) < maxMessageLength {
-		return message, ErrIncorrectMessageFormat
+	code, payload, ok := strings.Cut(msg, string(DelimiterCommand))
+	if !ok || code == "" || len(code) > len(strconv.Itoa(int(MaxCommand))) || (len(code) > 1 && code[0] == '0') {
+		return Message{}, ErrIncorrectMessageFormat
 	}
 
-	switch msg[:maxMessageLength] {
-	case fmt.Sprintf("0%c", DelimiterCommand):
-		message.Command = CommandError
-	case fmt.Sprintf("1%c", DelimiterCommand):
-		message.Command = CommandRequestPuzzle
-	case fmt.Sprintf("2%c", DelimiterCommand):
-		message.Command = CommandResponsePuzzle
-	case fmt.Sprintf("3%c", DelimiterCommand):
-		message.Command = CommandRequestResource
-	case fmt.Sprintf("4%c", DelimiterCommand):
-		message.Command = CommandResponseResource
-	default:
-		return message, ErrIncorrectMessageFormat
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return Message{}, ErrIncorrectMessageFormat
+		}
 	}
 
-	if len(msg) > maxMessageLength {
-		message.Payload = strings.TrimSpace(msg[maxMessageLength:])
+	command, err := strconv.Atoi(code)
+	if err != nil {
+		return Message{}, ErrIncorrectMessageFormat
 	}
 
-	return message, nil
+	return Message{Command: Command(command), Payload: strings.TrimSpace(payload)}, nil
 }
 
 // Message - message with command and payload.
@@ -1,6 +1,9 @@
 package message
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -32,15 +35,190 @@ func Test_Message(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, Message{Command: CommandResponseResource, Payload: "resource"}, act)
 		require.Equal(t, "4:resource\n", act.String())
+
+		act, err = ParseMessage("5:versions=1")
+		require.NoError(t, err)
+		require.Equal(t, Message{Command: CommandHello, Payload: "versions=1"}, act)
+		require.Equal(t, "5:versions=1\n", act.String())
+
+		act, err = ParseMessage("6:versions=1")
+		require.NoError(t, err)
+		require.Equal(t, Message{Command: CommandWelcome, Payload: "versions=1"}, act)
+		require.Equal(t, "6:versions=1\n", act.String())
 	})
 
 	t.Run("Parse message failed", func(t *testing.T) {
-		act, err := ParseMessage("5:unknown")
+		act, err := ParseMessage("incorrect message")
 		require.EqualError(t, ErrIncorrectMessageFormat, err.Error())
 		require.Equal(t, Message{}, act)
 
-		act, err = ParseMessage("incorrect message")
-		require.EqualError(t, ErrIncorrectMessageFormat, err.Error())
+		for _, raw := range []string{":payload", "01:payload", "-1:payload", "+1:payload", "1a:payload", "10000:payload"} {
+			act, err = ParseMessage(raw)
+			require.ErrorIs(t, err, ErrIncorrectMessageFormat, raw)
+			require.Equal(t, Message{}, act, raw)
+		}
+
+		act, err = ParseMessage("5:versions")
+		require.ErrorIs(t, err, ErrIncorrectHelloFormat)
 		require.Equal(t, Message{}, act)
 	})
+
+	t.Run("Parse unsupported command", func(t *testing.T) {
+		act, err := ParseMessage("7:unknown")
+		require.ErrorIs(t, err, ErrUnsupportedCommand)
+		require.Equal(t, Message{Command: 7, Payload: "unknown"}, act)
+
+		act, err = ParseMessage("9999:")
+		require.ErrorIs(t, err, ErrUnsupportedCommand)
+		require.Equal(t, Message{Command: 9999}, act)
+	})
+}
+
+func Test_Registry(t *testing.T) {
+	t.Run("Register command ok", func(t *testing.T) {
+		r := DefaultRegistry()
+
+		spec := Spec{Command: 42, Name: "Ping", Direction: DirectionClientToServer, Validate: func(payload string) error {
+			if payload == "" {
+				return ErrIncorrectMessageFormat
+			}
+
+			return nil
+		}}
+		require.NoError(t, r.Register(spec))
+		require.Equal(t, []Command{0, 1, 2, 3, 4, 5, 6, 42}, r.Commands())
+
+		act, ok := r.Get(42)
+		require.True(t, ok)
+		require.Equal(t, "Ping", act.Name)
+
+		msg, err := r.Parse("42:ping")
+		require.NoError(t, err)
+		require.Equal(t, Message{Command: 42, Payload: "ping"}, msg)
+		require.Equal(t, "42:ping\n", msg.String())
+
+		_, err = r.Parse("42:")
+		require.ErrorIs(t, err, ErrIncorrectMessageFormat)
+	})
+
+	t.Run("Register command failed", func(t *testing.T) {
+		r := DefaultRegistry()
+
+		require.ErrorIs(t, r.Register(Spec{Command: 1, Name: "Duplicate", Direction: DirectionClientToServer}),
+			ErrCommandRegistered)
+		require.ErrorIs(t, r.Register(Spec{Command: 10000, Name: "Big", Direction: DirectionClientToServer}),
+			ErrIncorrectSpec)
+		require.ErrorIs(t, r.Register(Spec{Command: 10, Direction: DirectionClientToServer}), ErrIncorrectSpec)
+		require.ErrorIs(t, r.Register(Spec{Command: 10, Name: "NoDirection"}), ErrIncorrectSpec)
+
+		_, err := NewRegistry(Specs()[0], Specs()[0])
+		require.ErrorIs(t, err, ErrCommandRegistered)
+	})
+
+	t.Run("Parse direction", func(t *testing.T) {
+		r := DefaultRegistry()
+
+		msg, err := r.ParseDirection("1:hashcash", DirectionClientToServer)
+		require.NoError(t, err)
+		require.Equal(t, Message{Command: CommandRequestPuzzle, Payload: "hashcash"}, msg)
+
+		for _, raw := range []string{"0:error", "2:puzzle", "4:resource", "6:versions=1"} {
+			msg, err = r.ParseDirection(raw, DirectionClientToServer)
+			require.ErrorIs(t, err, ErrWrongDirection, raw)
+			require.Equal(t, raw[:1], strconv.Itoa(int(msg.Command)), raw)
+		}
+
+		_, err = r.ParseDirection("3:puzzle", DirectionServerToClient)
+		require.ErrorIs(t, err, ErrWrongDirection)
+
+		_, err = r.ParseDirection("7:unknown", DirectionClientToServer)
+		require.ErrorIs(t, err, ErrUnsupportedCommand)
+	})
+}
+
+func Test_Hello(t *testing.T) {
+	t.Run("Parse hello ok", func(t *testing.T) {
+		act, err := ParseHello("versions=2,1;schemes=timelock,hashcash;codecs=text")
+		require.NoError(t, err)
+		require.Equal(t, Hello{
+			Versions: []string{"2", "1"},
+			Schemes:  []string{"timelock", "hashcash"},
+			Codecs:   []string{"text"},
+		}, act)
+		require.Equal(t, "versions=2,1;schemes=timelock,hashcash;codecs=text", act.String())
+
+		act, err = ParseHello("versions=1;schemes=;compression=zstd")
+		require.NoError(t, err)
+		require.Equal(t, Hello{Versions: []string{"1"}}, act)
+	})
+
+	t.Run("Parse hello failed", func(t *testing.T) {
+		for _, payload := range []string{"", "versions", "=1", "versions=1,;schemes=hashcash", "versions=1;;codecs=text"} {
+			_, err := ParseHello(payload)
+			require.ErrorIs(t, err, ErrIncorrectHelloFormat, payload)
+		}
+	})
+
+	t.Run("Negotiate", func(t *testing.T) {
+		act, ok := Negotiate([]string{"2", "1"}, []string{"1", "2"})
+		require.True(t, ok)
+		require.Equal(t, "2", act)
+
+		_, ok = Negotiate([]string{"2"}, []string{"1"})
+		require.False(t, ok)
+	})
+}
+
+// FuzzParseMessage - parsed message is serialized and parsed back to the same message,
+// command is a registered or unsupported code in range and payload is trimmed.
+func FuzzParseMessage(f *testing.F) {
+	for _, seed := range []string{
+		"0:error\n",
+		"1:\n",
+		"1:timelock,subpuzzle,hashcash\n",
+		"2:hashcash 1:5:20231102192537:resource::Cxphfw==:MA==\n",
+		"3: padded \n",
+		"4:resource",
+		"5:versions=1;schemes=hashcash;codecs=text\n",
+		"6:versions=1;schemes=hashcash;codecs=text\n",
+		"5:versions\n",
+		"42:ping\n",
+		"9999:\n",
+		"10000:\n",
+		"01:\n",
+		"-1:\n",
+		"1",
+		"\n",
+		"1:a:b:c\n",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		msg, err := ParseMessage(raw)
+		if err != nil && !errors.Is(err, ErrUnsupportedCommand) {
+			require.Equal(t, Message{}, msg)
+
+			return
+		}
+
+		require.GreaterOrEqual(t, msg.Command, Command(0))
+		require.LessOrEqual(t, msg.Command, MaxCommand)
+		require.Equal(t, strings.TrimSpace(msg.Payload), msg.Payload)
+
+		_, registered := builtin.Get(msg.Command)
+		require.Equal(t, err == nil, registered)
+
+		serialized := msg.String()
+		require.True(t, strings.HasSuffix(serialized, string(DelimiterMessage)))
+
+		parsed, parseErr := ParseMessage(serialized)
+		require.Equal(t, err, parseErr)
+		require.Equal(t, msg, parsed)
+		require.Equal(t, serialized, parsed.String(), "serialized message isn't canonical")
+
+		if !strings.ContainsRune(strings.TrimSuffix(raw, string(DelimiterMessage)), DelimiterMessage) {
+			require.NotContains(t, msg.Payload, string(DelimiterMessage))
+		}
+	})
 }
@@ -0,0 +1,143 @@
+package message
+
+import (
+	"sort"
+)
+
+// Direction - command direction.
+type Direction int8
+
+const (
+	// DirectionClientToServer - command is sent by client to server.
+	DirectionClientToServer Direction = iota + 1
+
+	// DirectionServerToClient - command is sent by server to client.
+	DirectionServerToClient
+)
+
+// Spec - command specification.
+// Validate - optional payload validation.
+type Spec struct {
+	Command   Command
+	Name      string
+	Direction Direction
+	Validate  func(payload string) error
+}
+
+// Specs - built-in commands specifications.
+func Specs() []Spec {
+	return []Spec{
+		{Command: CommandError, Name: "Error", Direction: DirectionServerToClient},
+		{Command: CommandRequestPuzzle, Name: "RequestPuzzle", Direction: DirectionClientToServer},
+		{Command: CommandResponsePuzzle, Name: "ResponsePuzzle", Direction: DirectionServerToClient},
+		{Command: CommandRequestResource, Name: "RequestResource", Direction: DirectionClientToServer},
+		{Command: CommandResponseResource, Name: "ResponseResource", Direction: DirectionServerToClient},
+		{Command: CommandHello, Name: "Hello", Direction: DirectionClientToServer, Validate: validateHello},
+		{Command: CommandWelcome, Name: "Welcome", Direction: DirectionServerToClient, Validate: validateHello},
+	}
+}
+
+// NewRegistry - create new commands registry.
+func NewRegistry(specs ...Spec) (*Registry, error) {
+	r := &Registry{
+		specs: make(map[Command]Spec),
+	}
+
+	for _, spec := range specs {
+		if err := r.Register(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// DefaultRegistry - create new registry with built-in commands.
+func DefaultRegistry() *Registry {
+	r, _ := NewRegistry(Specs()...)
+
+	return r
+}
+
+// Registry - commands registry, it isn't safe for concurrent registration and parsing.
+type Registry struct {
+	specs map[Command]Spec
+}
+
+// Register - declare new command.
+func (r *Registry) Register(spec Spec) error {
+	if spec.Command < 0 || spec.Command > MaxCommand || spec.Name == "" ||
+		(spec.Direction != DirectionClientToServer && spec.Direction != DirectionServerToClient) {
+		return ErrIncorrectSpec
+	}
+
+	if _, ok := r.specs[spec.Command]; ok {
+		return ErrCommandRegistered
+	}
+
+	r.specs[spec.Command] = spec
+
+	return nil
+}
+
+// Get - returns command specification.
+func (r *Registry) Get(command Command) (Spec, bool) {
+	spec, ok := r.specs[command]
+
+	return spec, ok
+}
+
+// Commands - returns registered commands in ascending order.
+func (r *Registry) Commands() []Command {
+	commands := make([]Command, 0, len(r.specs))
+	for command := range r.specs {
+		commands = append(commands, command)
+	}
+
+	sort.Slice(commands, func(i, j int) bool { return commands[i] < commands[j] })
+
+	return commands
+}
+
+// Parse - parse message of registered command and validate its payload.
+// Returns ErrUnsupportedCommand with parsed message if command isn't registered.
+func (r *Registry) Parse(raw string) (Message, error) {
+	msg, err := parse(raw)
+	if err != nil {
+		return Message{}, err
+	}
+
+	spec, ok := r.specs[msg.Command]
+	if !ok {
+		return msg, ErrUnsupportedCommand
+	}
+
+	if spec.Validate != nil {
+		if err = spec.Validate(msg.Payload); err != nil {
+			return Message{}, err
+		}
+	}
+
+	return msg, nil
+}
+
+// ParseDirection - parse message like Parse and check that command is sent in direction.
+// Returns ErrWrongDirection with parsed message if command is sent in opposite direction.
+func (r *Registry) ParseDirection(raw string, direction Direction) (Message, error) {
+	msg, err := r.Parse(raw)
+	if err != nil {
+		return msg, err
+	}
+
+	if r.specs[msg.Command].Direction != direction {
+		return msg, ErrWrongDirection
+	}
+
+	return msg, nil
+}
+
+func validateHello(payload string) error {
+	_, err := ParseHello(payload)
+
+	return err
+}
@@ -0,0 +1,144 @@
+package penalty
+
+import (
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
+)
+
+// Banlist - ban list to ban clients with too many violations.
+type Banlist interface {
+	Ban(prefix string) error
+}
+
+// Opts - options to create new penalty box.
+// BanThreshold - number of violations in window to ban client ip, 0 - clients are never banned.
+// Window - client violations are forgotten when window passed since the first one.
+// Banlist - required if BanThreshold is set.
+// Clock - optional, system clock is used by default.
+type Opts struct {
+	BanThreshold int
+	Window       time.Duration
+	Banlist      Banlist
+	Clock        clock.Clock
+}
+
+// New - create new penalty box.
+func New(opts Opts) *Box {
+	return &Box{
+		opts:    opts,
+		clients: make(map[netip.Addr]*record),
+		total:   make(map[string]int64),
+		clock:   clock.OrReal(opts.Clock),
+	}
+}
+
+// Box - thread-safe counter of client violations by ip.
+type Box struct {
+	opts Opts
+
+	mu        sync.Mutex
+	clients   map[netip.Addr]*record
+	total     map[string]int64
+	lastClean time.Time
+	clock     clock.Clock
+}
+
+// record - client violations in window.
+type record struct {
+	count int
+	since time.Time
+}
+
+// Violation - register violation of client with address addr (ip or host:port) and reason.
+// Returns number of client violations in window and whether client has been banned by this violation.
+func (b *Box) Violation(addr, reason string) (count int, banned bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.total[reason]++
+
+	ip, ok := parseAddr(addr)
+	if !ok {
+		return 0, false
+	}
+
+	now := b.clock.Now()
+	b.clean(now)
+
+	r, ok := b.clients[ip]
+	if !ok || now.Sub(r.since) >= b.opts.Window {
+		r = &record{since: now}
+		b.clients[ip] = r
+	}
+
+	r.count++
+
+	if b.opts.BanThreshold > 0 && r.count == b.opts.BanThreshold && b.opts.Banlist != nil {
+		banned = b.opts.Banlist.Ban(ip.String()) == nil
+	}
+
+	return r.count, banned
+}
+
+// Stats - violations statistics.
+// Total - number of all violations by reason, Clients - number of violations in window by client ip.
+type Stats struct {
+	Total   map[string]int64 `json:"total"`
+	Clients map[string]int   `json:"clients"`
+}
+
+// Stats - get violations statistics.
+func (b *Box) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	stats := Stats{
+		Total:   make(map[string]int64, len(b.total)),
+		Clients: make(map[string]int),
+	}
+
+	for reason, count := range b.total {
+		stats.Total[reason] = count
+	}
+
+	for ip, r := range b.clients {
+		if now.Sub(r.since) < b.opts.Window {
+			stats.Clients[ip.String()] = r.count
+		}
+	}
+
+	return stats
+}
+
+// clean - forget expired client records not often than once per window.
+func (b *Box) clean(now time.Time) {
+	if now.Sub(b.lastClean) < b.opts.Window {
+		return
+	}
+
+	b.lastClean = now
+
+	for ip, r := range b.clients {
+		if now.Sub(r.since) >= b.opts.Window {
+			delete(b.clients, ip)
+		}
+	}
+}
+
+func parseAddr(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return ip, false
+	}
+
+	return ip.Unmap(), true
+}
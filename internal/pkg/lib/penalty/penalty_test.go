@@ -0,0 +1,71 @@
+package penalty
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/banlist"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
+)
+
+func Test_Box(t *testing.T) {
+	t.Run("client banned by threshold", func(t *testing.T) {
+		bans := banlist.New()
+		box := New(Opts{BanThreshold: 2, Window: time.Minute, Banlist: bans})
+
+		count, banned := box.Violation("10.0.0.1:1234", "protocol violation")
+		require.Equal(t, 1, count)
+		require.False(t, banned)
+		require.False(t, bans.IsBanned("10.0.0.1"))
+
+		count, banned = box.Violation("10.0.0.1:4321", "protocol violation")
+		require.Equal(t, 2, count)
+		require.True(t, banned)
+		require.True(t, bans.IsBanned("10.0.0.1"))
+
+		count, banned = box.Violation("10.0.0.2:1234", "connection limit")
+		require.Equal(t, 1, count)
+		require.False(t, banned)
+
+		require.Equal(t, Stats{
+			Total:   map[string]int64{"protocol violation": 2, "connection limit": 1},
+			Clients: map[string]int{"10.0.0.1": 2, "10.0.0.2": 1},
+		}, box.Stats())
+	})
+
+	t.Run("violations forgotten after window", func(t *testing.T) {
+		fakeClock := clock.NewFake(time.Now())
+		box := New(Opts{Window: time.Minute, Clock: fakeClock})
+
+		box.Violation("10.0.0.1:1234", "protocol violation")
+		box.Violation("10.0.0.1:1234", "protocol violation")
+
+		fakeClock.Advance(time.Minute)
+		require.Empty(t, box.Stats().Clients)
+
+		count, _ := box.Violation("10.0.0.1:1234", "protocol violation")
+		require.Equal(t, 1, count)
+		require.Equal(t, int64(3), box.Stats().Total["protocol violation"])
+	})
+
+	t.Run("client isn't banned without threshold", func(t *testing.T) {
+		bans := banlist.New()
+		box := New(Opts{Window: time.Minute, Banlist: bans})
+
+		for range 10 {
+			box.Violation("10.0.0.1:1234", "protocol violation")
+		}
+
+		require.Empty(t, bans.Prefixes())
+	})
+
+	t.Run("violation of unknown address counted", func(t *testing.T) {
+		box := New(Opts{Window: time.Minute})
+
+		count, _ := box.Violation("pipe", "protocol violation")
+		require.Zero(t, count)
+		require.Equal(t, int64(1), box.Stats().Total["protocol violation"])
+	})
+}
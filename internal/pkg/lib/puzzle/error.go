@@ -0,0 +1,27 @@
+package puzzle
+
+import (
+	"errors"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+var (
+	ErrUnknownScheme     = errors.New("unknown puzzle scheme")
+	ErrNoCommonScheme    = errors.New("no common puzzle scheme")
+	ErrIncorrectEnvelope = errors.New("incorrect puzzle envelope")
+
+	ErrIncorrectSubPuzzles      = errors.New("sub-puzzles count must be power of two and less than difficulty")
+	ErrIncorrectSubPuzzle       = errors.New("incorrect sub-puzzle format")
+	ErrSolveMaxAttemptsExceeded = errors.New("max attempts to solve puzzle exceeded")
+
+	ErrIncorrectTimeLock           = errors.New("incorrect time-lock puzzle format")
+	ErrIncorrectTimeLockModulus    = errors.New("incorrect time-lock modulus")
+	ErrIncorrectTimeLockIterations = errors.New("time-lock iterations must be more than zero")
+
+	// Precheck errors are shared by all schemes.
+	ErrResourceTooLong = hashcash.ErrResourceTooLong
+	ErrCounterTooLarge = hashcash.ErrCounterTooLarge
+	ErrDateInFuture    = hashcash.ErrDateInFuture
+	ErrIncorrectRand   = hashcash.ErrIncorrectRand
+)
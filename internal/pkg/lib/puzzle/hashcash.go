@@ -0,0 +1,121 @@
+package puzzle
+
+import (
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+// SchemeHashcash - hashcash scheme id.
+const SchemeHashcash = "hashcash"
+
+// HashcashConfig - hashcash puzzles config interface.
+type HashcashConfig interface {
+	PuzzleZeroBits() int
+	PuzzleAlgorithm() hashcash.Algorithm
+	PuzzleTTL() time.Duration
+}
+
+// NewHashcashScheme - create hashcash scheme.
+// Config and signer are used only to issue and verify puzzles and could be nil on client side.
+func NewHashcashScheme(config HashcashConfig, signer *hashcash.Signer) *HashcashScheme {
+	return &HashcashScheme{
+		config: config,
+		signer: signer,
+	}
+}
+
+// HashcashScheme - hashcash puzzles, default scheme.
+// Issued puzzle carries scheme id and expiry in the extension, signed if signer is set.
+// Client reproduces extension verbatim.
+type HashcashScheme struct {
+	config HashcashConfig
+	signer *hashcash.Signer
+}
+
+// ID - scheme identifier.
+func (s *HashcashScheme) ID() string {
+	return SchemeHashcash
+}
+
+// Issue - issue new hashcash puzzle.
+func (s *HashcashScheme) Issue(resource string, now time.Time) (Puzzle, error) {
+	h, err := hashcash.NewWithAlgorithm(s.config.PuzzleZeroBits(), resource, s.config.PuzzleAlgorithm(), now)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // hashcash error.
+	}
+
+	h.SetExtension(hashcash.ExtensionScheme, SchemeHashcash)
+	h.SetExpiry(now.Add(s.config.PuzzleTTL()))
+
+	if s.signer != nil {
+		s.signer.Sign(h)
+	}
+
+	return &hashcashPuzzle{h: h, signer: s.signer}, nil
+}
+
+// Parse - parse hashcash header.
+func (s *HashcashScheme) Parse(serialized string) (Puzzle, error) {
+	h, err := hashcash.ParseHeader(serialized)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // hashcash error.
+	}
+
+	return &hashcashPuzzle{h: h, signer: s.signer}, nil
+}
+
+type hashcashPuzzle struct {
+	h      *hashcash.Hashcash
+	signer *hashcash.Signer
+}
+
+func (p *hashcashPuzzle) Key() string {
+	return p.h.Key()
+}
+
+func (p *hashcashPuzzle) IssueKey() string {
+	return p.h.IssueKey()
+}
+
+func (p *hashcashPuzzle) Serialize() string {
+	return string(p.h.Header())
+}
+
+func (p *hashcashPuzzle) EqualResource(resource string) bool {
+	return p.h.EqualResource(resource)
+}
+
+func (p *hashcashPuzzle) IsActual(now time.Time, ttl time.Duration) bool {
+	return p.h.IsActual(now, ttl)
+}
+
+func (p *hashcashPuzzle) Difficulty() int {
+	return p.h.Bits()
+}
+
+func (p *hashcashPuzzle) Precheck(now time.Time) error {
+	return p.h.Precheck(now) //nolint:wrapcheck // hashcash error.
+}
+
+func (p *hashcashPuzzle) Solve(maxAttempts int, progress ProgressFunc) error {
+	reporter := newProgressReporter(progress)
+	expected := hashcash.ExpectedAttempts(p.h.Bits())
+
+	err := p.h.ComputeWithProgress(maxAttempts, func(attempts int) { reporter.report(attempts, expected) })
+	if err != nil {
+		return err //nolint:wrapcheck // hashcash error.
+	}
+
+	reporter.done(p.h.Counter() + 1)
+
+	return nil
+}
+
+func (p *hashcashPuzzle) Verify() (bool, error) {
+	if p.signer != nil && !p.signer.Verify(p.h) {
+		return false, nil
+	}
+
+	return p.h.IsSolved() //nolint:wrapcheck // hashcash error.
+}
@@ -0,0 +1,88 @@
+package puzzle
+
+import "time"
+
+const (
+	// ProgressInterval - min interval between progress reports.
+	ProgressInterval = 200 * time.Millisecond
+
+	// progressStep - number of attempts between checks if progress should be reported.
+	progressStep = 1 << 10
+)
+
+// Progress - puzzle solving progress.
+// Remaining - expected remaining attempts at current difficulty,
+// for hashcash it doesn't decrease, because every attempt succeeds with the same probability.
+type Progress struct {
+	Attempts  int
+	Remaining float64
+	HashRate  float64 // attempts per second.
+	Elapsed   time.Duration
+	ETA       time.Duration // estimated time to solution at current hash rate.
+	Done      bool          // puzzle is solved, final report.
+}
+
+// ProgressFunc - callback to receive solving progress, called from solving goroutine.
+type ProgressFunc func(Progress)
+
+func newProgressReporter(fn ProgressFunc) *progressReporter {
+	now := time.Now()
+
+	return &progressReporter{
+		fn:    fn,
+		start: now,
+		last:  now,
+	}
+}
+
+// progressReporter - reports progress not more often than ProgressInterval.
+type progressReporter struct {
+	fn    ProgressFunc
+	start time.Time
+	last  time.Time
+}
+
+// report - report progress if interval passed, nil reporter does nothing.
+func (r *progressReporter) report(attempts int, remaining float64) {
+	if r == nil || r.fn == nil || attempts%progressStep != 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(r.last) < ProgressInterval {
+		return
+	}
+
+	r.last = now
+	r.fn(r.progress(now, attempts, remaining))
+}
+
+// done - report final progress, nil reporter does nothing.
+func (r *progressReporter) done(attempts int) {
+	if r == nil || r.fn == nil {
+		return
+	}
+
+	p := r.progress(time.Now(), attempts, 0)
+	p.Done = true
+
+	r.fn(p)
+}
+
+func (r *progressReporter) progress(now time.Time, attempts int, remaining float64) Progress {
+	p := Progress{
+		Attempts:  attempts,
+		Remaining: max(remaining, 0),
+		Elapsed:   now.Sub(r.start),
+	}
+
+	if p.Elapsed > 0 {
+		p.HashRate = float64(attempts) / p.Elapsed.Seconds()
+	}
+
+	if p.HashRate > 0 {
+		p.ETA = time.Duration(p.Remaining / p.HashRate * float64(time.Second))
+	}
+
+	return p
+}
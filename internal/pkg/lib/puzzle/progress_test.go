@@ -0,0 +1,65 @@
+package puzzle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Progress(t *testing.T) {
+	t.Run("progress computed", func(t *testing.T) {
+		r := newProgressReporter(nil)
+
+		p := r.progress(r.start.Add(2*time.Second), 2000, 5000)
+		require.Equal(t, Progress{
+			Attempts:  2000,
+			Remaining: 5000,
+			HashRate:  1000,
+			Elapsed:   2 * time.Second,
+			ETA:       5 * time.Second,
+		}, p)
+	})
+
+	t.Run("reports are rate limited and final report sent", func(t *testing.T) {
+		var reports []Progress
+
+		r := newProgressReporter(func(p Progress) { reports = append(reports, p) })
+
+		r.report(progressStep, 1)
+		require.Empty(t, reports)
+
+		r.last = r.last.Add(-ProgressInterval)
+		r.report(progressStep+1, 1)
+		require.Empty(t, reports)
+
+		r.report(2*progressStep, 1)
+		require.Len(t, reports, 1)
+		require.Equal(t, 2*progressStep, reports[0].Attempts)
+		require.False(t, reports[0].Done)
+
+		r.done(2*progressStep + 5)
+		require.Len(t, reports, 2)
+		require.True(t, reports[1].Done)
+		require.Zero(t, reports[1].Remaining)
+	})
+
+	t.Run("solve reports final progress", func(t *testing.T) {
+		server, err := NewTimeLockScheme(&mockTimeLockConfig{iterations: 100}, MinTimeLockModulusBits)
+		require.NoError(t, err)
+
+		for _, scheme := range []Scheme{
+			NewHashcashScheme(&mockHashcashConfig{}, nil),
+			NewSubPuzzleScheme(&mockSubPuzzleConfig{bits: 2, count: 4}),
+			server,
+		} {
+			p, err := scheme.Issue("resource", time.Now())
+			require.NoError(t, err)
+
+			var last Progress
+			require.NoError(t, p.Solve(1000000, func(progress Progress) { last = progress }))
+			require.True(t, last.Done, scheme.ID())
+			require.Positive(t, last.Attempts, scheme.ID())
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package puzzle
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DelimiterScheme - sign to divide scheme id and serialized puzzle in envelope.
+	DelimiterScheme = " "
+
+	// DelimiterSchemes - sign to divide scheme ids in list.
+	DelimiterSchemes = ","
+)
+
+// Puzzle - issued or received puzzle.
+type Puzzle interface {
+	// Key - string presentation of puzzle without solution.
+	// Key is using to match original puzzle with solved puzzle.
+	Key() string
+	// IssueKey - Key without claimed difficulty, it's using to find issued puzzle
+	// and compare its difficulty with claimed one.
+	IssueKey() string
+	// Serialize - string presentation of puzzle to share it.
+	Serialize() string
+	// EqualResource - check if input resource is equal with puzzle resource.
+	EqualResource(resource string) bool
+	// IsActual - check if puzzle expiration exceeded ttl, now is current time.
+	IsActual(now time.Time, ttl time.Duration) bool
+	// Difficulty - claimed difficulty, e.g. zero bits or iterations, to compare with issued one.
+	Difficulty() int
+	// Precheck - cheap checks of puzzle fields before verification, now is current time.
+	Precheck(now time.Time) error
+	// Solve - find puzzle solution, progress is optional callback to report solving progress.
+	Solve(maxAttempts int, progress ProgressFunc) error
+	// Verify - check puzzle solution.
+	Verify() (bool, error)
+}
+
+// Scheme - puzzle scheme to issue and parse puzzles.
+type Scheme interface {
+	// ID - scheme identifier.
+	ID() string
+	// Issue - issue new puzzle for resource, now is current time.
+	Issue(resource string, now time.Time) (Puzzle, error)
+	// Parse - parse serialized puzzle.
+	Parse(serialized string) (Puzzle, error)
+}
+
+// NewRegistry - create new registry with schemes in preference order.
+func NewRegistry(schemes ...Scheme) *Registry {
+	r := &Registry{
+		schemes: make(map[string]Scheme),
+	}
+
+	for _, scheme := range schemes {
+		r.Register(scheme)
+	}
+
+	return r
+}
+
+// Registry - puzzle schemes by id.
+type Registry struct {
+	schemes map[string]Scheme
+	order   []string
+	mu      sync.RWMutex
+}
+
+// Register - add scheme with the lowest preference, scheme with the same id is replaced.
+func (r *Registry) Register(scheme Scheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.schemes[scheme.ID()]; !ok {
+		r.order = append(r.order, scheme.ID())
+	}
+
+	r.schemes[scheme.ID()] = scheme
+}
+
+// Get - returns scheme by id.
+func (r *Registry) Get(id string) (Scheme, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scheme, ok := r.schemes[id]
+
+	return scheme, ok
+}
+
+// IDs - returns scheme ids in preference order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// Negotiate - returns the most preferred scheme supported by peer.
+func (r *Registry) Negotiate(peerIDs []string) (Scheme, error) {
+	for _, id := range r.IDs() {
+		for _, peerID := range peerIDs {
+			if id != peerID {
+				continue
+			}
+
+			if scheme, ok := r.Get(id); ok {
+				return scheme, nil
+			}
+		}
+	}
+
+	return nil, ErrNoCommonScheme
+}
+
+// Wrap - returns puzzle serialized with scheme id.
+// Format - "id serialized".
+func Wrap(id string, p Puzzle) string {
+	return id + DelimiterScheme + p.Serialize()
+}
+
+// Unwrap - returns scheme id and serialized puzzle from envelope.
+func Unwrap(envelope string) (id, serialized string, err error) {
+	id, serialized, ok := strings.Cut(envelope, DelimiterScheme)
+	if !ok || id == "" {
+		return "", "", ErrIncorrectEnvelope
+	}
+
+	return id, serialized, nil
+}
+
+// JoinIDs - returns scheme ids as list.
+func JoinIDs(ids []string) string {
+	return strings.Join(ids, DelimiterSchemes)
+}
+
+// SplitIDs - returns scheme ids from list.
+func SplitIDs(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	return strings.Split(list, DelimiterSchemes)
+}
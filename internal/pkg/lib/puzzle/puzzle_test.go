@@ -0,0 +1,141 @@
+package puzzle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHashcashConfig struct{}
+
+func (c *mockHashcashConfig) PuzzleZeroBits() int                 { return 1 }
+func (c *mockHashcashConfig) PuzzleAlgorithm() hashcash.Algorithm { return hashcash.SHA256{} }
+func (c *mockHashcashConfig) PuzzleTTL() time.Duration            { return time.Minute }
+
+type mockScheme struct {
+	id string
+}
+
+func (s *mockScheme) ID() string                                  { return s.id }
+func (s *mockScheme) Issue(_ string, _ time.Time) (Puzzle, error) { return nil, nil }
+func (s *mockScheme) Parse(_ string) (Puzzle, error)              { return nil, nil }
+
+func Test_Registry(t *testing.T) {
+	t.Run("negotiate ok", func(t *testing.T) {
+		r := NewRegistry(&mockScheme{id: "a"}, &mockScheme{id: "b"}, &mockScheme{id: "c"})
+		require.Equal(t, []string{"a", "b", "c"}, r.IDs())
+
+		scheme, err := r.Negotiate([]string{"c", "b"})
+		require.NoError(t, err)
+		require.Equal(t, "b", scheme.ID())
+
+		_, err = r.Negotiate([]string{"d"})
+		require.ErrorIs(t, err, ErrNoCommonScheme)
+
+		_, err = r.Negotiate(nil)
+		require.ErrorIs(t, err, ErrNoCommonScheme)
+	})
+
+	t.Run("envelope ok", func(t *testing.T) {
+		id, serialized, err := Unwrap("hashcash 1:5:20231102192537:resource::Cxphfw==:MA==")
+		require.NoError(t, err)
+		require.Equal(t, "hashcash", id)
+		require.Equal(t, "1:5:20231102192537:resource::Cxphfw==:MA==", serialized)
+
+		_, _, err = Unwrap("1:5:20231102192537:resource::Cxphfw==:MA==")
+		require.ErrorIs(t, err, ErrIncorrectEnvelope)
+
+		require.Equal(t, []string{"a", "b"}, SplitIDs(JoinIDs([]string{"a", "b"})))
+		require.Empty(t, SplitIDs(""))
+	})
+}
+
+func Test_HashcashScheme(t *testing.T) {
+	t.Run("issue, solve and verify ok", func(t *testing.T) {
+		server := NewHashcashScheme(&mockHashcashConfig{}, hashcash.NewSigner("1", []byte("key")))
+		client := NewHashcashScheme(nil, nil)
+
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+
+		received, err := client.Parse(issued.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), received.Key())
+		require.NoError(t, received.Solve(1000, nil))
+
+		solved, err := server.Parse(received.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), solved.Key())
+		require.Equal(t, issued.IssueKey(), solved.IssueKey())
+		require.True(t, solved.EqualResource("resource"))
+		require.True(t, solved.IsActual(time.Now(), time.Minute))
+
+		ok, err := solved.Verify()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("issue time is passed now", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		server := NewHashcashScheme(&mockHashcashConfig{}, nil)
+
+		issued, err := server.Issue("resource", now)
+		require.NoError(t, err)
+
+		h, err := hashcash.ParseHeader(issued.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, now, h.Date())
+
+		exp, ok := h.Expiry()
+		require.True(t, ok)
+		require.Equal(t, now.Add(time.Minute), exp)
+
+		require.True(t, issued.IsActual(now.Add(time.Minute-time.Second), time.Minute))
+		require.False(t, issued.IsActual(now.Add(time.Minute), time.Minute))
+	})
+
+	t.Run("issued extension and signature ok", func(t *testing.T) {
+		server := NewHashcashScheme(&mockHashcashConfig{}, hashcash.NewSigner("1", []byte("key")))
+
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+
+		ext, err := hashcash.ParseHeader(issued.Serialize())
+		require.NoError(t, err)
+
+		scheme, ok := ext.Extension().Get(hashcash.ExtensionScheme)
+		require.True(t, ok)
+		require.Equal(t, SchemeHashcash, scheme)
+
+		exp, ok := ext.Expiry()
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Minute), exp, 2*time.Second)
+
+		keyID, ok := ext.Extension().Get(hashcash.ExtensionKeyID)
+		require.True(t, ok)
+		require.Equal(t, "1", keyID)
+
+		for _, signer := range []*hashcash.Signer{
+			hashcash.NewSigner("1", []byte("other key")),
+			hashcash.NewSigner("2", []byte("key")),
+		} {
+			h, err := hashcash.ParseHeader(issued.Serialize())
+			require.NoError(t, err)
+			require.False(t, signer.Verify(h))
+		}
+
+		tampered, err := hashcash.ParseHeader(issued.Serialize())
+		require.NoError(t, err)
+		tampered.SetExpiry(time.Now().Add(time.Hour))
+
+		solved, err := server.Parse(string(tampered.Header()))
+		require.NoError(t, err)
+		require.NoError(t, solved.Solve(1000, nil))
+
+		ok, err = solved.Verify()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,302 @@
+package puzzle
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+// SchemeSubPuzzle - sub-puzzles scheme id.
+const SchemeSubPuzzle = "subpuzzle"
+
+// MaxSubPuzzles - max number of sub-puzzles in puzzle.
+const MaxSubPuzzles = 256
+
+const (
+	subPuzzleParts      = 6
+	delimiterSubPuzzle  = ":"
+	delimiterCounters   = ","
+	hexDigitBits        = 4
+	maxCounterLength    = 20
+	maxSubPuzzleRandLen = 8
+)
+
+// SubPuzzleConfig - sub-puzzles config interface.
+// PuzzleZeroBits - difficulty of the whole puzzle in hashcash zero bits (hex digits).
+// PuzzleSubPuzzles - number of sub-puzzles, power of two.
+type SubPuzzleConfig interface {
+	PuzzleZeroBits() int
+	PuzzleSubPuzzles() int
+}
+
+// NewSubPuzzleScheme - create sub-puzzles scheme.
+// Config is used only to issue puzzles and could be nil on client side.
+func NewSubPuzzleScheme(config SubPuzzleConfig) *SubPuzzleScheme {
+	return &SubPuzzleScheme{
+		config: config,
+	}
+}
+
+// SubPuzzleScheme - puzzle consists of k independent sub-puzzles with lower difficulty.
+// Client must solve all of them. Expected work is the same as for hashcash with the same bits,
+// but solve time variance is k times lower.
+type SubPuzzleScheme struct {
+	config SubPuzzleConfig
+}
+
+// ID - scheme identifier.
+func (s *SubPuzzleScheme) ID() string {
+	return SchemeSubPuzzle
+}
+
+// Issue - issue new sub-puzzles puzzle.
+// Every sub-puzzle requires bits*4 - log2(k) leading zero bits of sha256 hash.
+func (s *SubPuzzleScheme) Issue(resource string, now time.Time) (Puzzle, error) {
+	count := s.config.PuzzleSubPuzzles()
+	if !IsSubPuzzlesCountValid(count) {
+		return nil, ErrIncorrectSubPuzzles
+	}
+
+	subBits := SubPuzzleBits(s.config.PuzzleZeroBits(), count)
+	if subBits <= 0 {
+		return nil, ErrIncorrectSubPuzzles
+	}
+
+	randomBytes := make([]byte, hashcash.RandLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, fmt.Errorf("get random error: %w", err)
+	}
+
+	return &subPuzzle{
+		subBits:  subBits,
+		count:    count,
+		date:     now.UTC().Truncate(time.Second),
+		rand:     randomBytes,
+		resource: resource,
+	}, nil
+}
+
+// Parse - parse sub-puzzles puzzle.
+// Format - subBits:count:date:rand:counters:resource, counters are divided by comma.
+func (s *SubPuzzleScheme) Parse(serialized string) (Puzzle, error) {
+	var (
+		p   = &subPuzzle{}
+		err error
+	)
+
+	parts := strings.SplitN(serialized, delimiterSubPuzzle, subPuzzleParts)
+	if len(parts) != subPuzzleParts {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	p.subBits, err = strconv.Atoi(parts[0])
+	if err != nil || p.subBits <= 0 || p.subBits > sha256.Size*8 {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	p.count, err = strconv.Atoi(parts[1])
+	if err != nil || !IsSubPuzzlesCountValid(p.count) {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	date, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	p.date = time.Unix(date, 0).UTC()
+
+	p.rand, err = base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(p.rand) > maxSubPuzzleRandLen {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	if parts[4] != "" {
+		if p.counters, err = parseCounters(parts[4], p.count); err != nil {
+			return nil, err
+		}
+	}
+
+	p.resource = parts[5]
+
+	return p, nil
+}
+
+// IsSubPuzzlesCountValid - sub-puzzles count must be power of two not more than MaxSubPuzzles.
+func IsSubPuzzlesCountValid(count int) bool {
+	return count > 0 && count <= MaxSubPuzzles && bits.OnesCount(uint(count)) == 1
+}
+
+// SubPuzzleBits - returns leading zero bits of every sub-puzzle,
+// so k sub-puzzles need the same expected work as one hashcash puzzle with hashcashBits.
+func SubPuzzleBits(hashcashBits, count int) int {
+	return hashcashBits*hexDigitBits - bits.TrailingZeros(uint(count))
+}
+
+type subPuzzle struct {
+	subBits  int
+	count    int
+	date     time.Time
+	rand     []byte
+	resource string
+	counters []uint64
+}
+
+func (p *subPuzzle) Key() string {
+	return strings.Join([]string{
+		strconv.Itoa(p.subBits),
+		strconv.Itoa(p.count),
+		strconv.FormatInt(p.date.Unix(), 10),
+		base64.StdEncoding.EncodeToString(p.rand),
+		p.resource,
+	}, delimiterSubPuzzle)
+}
+
+func (p *subPuzzle) IssueKey() string {
+	return strings.Join([]string{
+		strconv.Itoa(p.count),
+		strconv.FormatInt(p.date.Unix(), 10),
+		base64.StdEncoding.EncodeToString(p.rand),
+		p.resource,
+	}, delimiterSubPuzzle)
+}
+
+func (p *subPuzzle) Serialize() string {
+	counters := make([]string, 0, len(p.counters))
+	for _, c := range p.counters {
+		counters = append(counters, strconv.FormatUint(c, 10))
+	}
+
+	return strings.Join([]string{
+		strconv.Itoa(p.subBits),
+		strconv.Itoa(p.count),
+		strconv.FormatInt(p.date.Unix(), 10),
+		base64.StdEncoding.EncodeToString(p.rand),
+		strings.Join(counters, delimiterCounters),
+		p.resource,
+	}, delimiterSubPuzzle)
+}
+
+func (p *subPuzzle) EqualResource(resource string) bool {
+	return p.resource == resource
+}
+
+func (p *subPuzzle) IsActual(now time.Time, ttl time.Duration) bool {
+	return p.date.Add(ttl).After(now)
+}
+
+func (p *subPuzzle) Difficulty() int {
+	return p.subBits
+}
+
+func (p *subPuzzle) Precheck(now time.Time) error {
+	switch {
+	case len(p.resource) > hashcash.MaxResourceLength:
+		return ErrResourceTooLong
+	case slices.ContainsFunc(p.counters, func(c uint64) bool { return c > hashcash.MaxCounter }):
+		return ErrCounterTooLarge
+	case p.date.After(now):
+		return ErrDateInFuture
+	case len(p.rand) != hashcash.RandLength:
+		return ErrIncorrectRand
+	default:
+		return nil
+	}
+}
+
+// Solve - find solution of every sub-puzzle, max attempts are shared by all sub-puzzles.
+// Expected remaining attempts are attempts for unsolved sub-puzzles.
+func (p *subPuzzle) Solve(maxAttempts int, progress ProgressFunc) error {
+	p.counters = make([]uint64, p.count)
+	key := p.Key()
+	attempts := 0
+	reporter := newProgressReporter(progress)
+	expected := math.Exp2(float64(p.subBits))
+
+	for i := range p.counters {
+		for !p.isSubPuzzleSolved(key, i) {
+			if attempts >= maxAttempts {
+				return ErrSolveMaxAttemptsExceeded
+			}
+
+			p.counters[i]++
+			attempts++
+			reporter.report(attempts, float64(p.count-i)*expected)
+		}
+	}
+
+	reporter.done(attempts)
+
+	return nil
+}
+
+// Verify - check all sub-puzzles in one pass.
+func (p *subPuzzle) Verify() (bool, error) {
+	if len(p.counters) != p.count {
+		return false, nil
+	}
+
+	key := p.Key()
+
+	for i := range p.counters {
+		if !p.isSubPuzzleSolved(key, i) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (p *subPuzzle) isSubPuzzleSolved(key string, i int) bool {
+	data := key + delimiterSubPuzzle + strconv.Itoa(i) + delimiterSubPuzzle + strconv.FormatUint(p.counters[i], 10)
+	hash := sha256.Sum256([]byte(data))
+
+	return leadingZeroBits(hash[:]) >= p.subBits
+}
+
+func parseCounters(list string, count int) ([]uint64, error) {
+	values := strings.Split(list, delimiterCounters)
+	if len(values) != count {
+		return nil, ErrIncorrectSubPuzzle
+	}
+
+	counters := make([]uint64, 0, count)
+
+	for _, v := range values {
+		if len(v) > maxCounterLength {
+			return nil, ErrIncorrectSubPuzzle
+		}
+
+		c, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, ErrIncorrectSubPuzzle
+		}
+
+		counters = append(counters, c)
+	}
+
+	return counters, nil
+}
+
+func leadingZeroBits(hash []byte) int {
+	zeros := 0
+
+	for _, b := range hash {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+
+		zeros += 8
+	}
+
+	return zeros
+}
@@ -0,0 +1,109 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockSubPuzzleConfig struct {
+	bits  int
+	count int
+}
+
+func (c *mockSubPuzzleConfig) PuzzleZeroBits() int   { return c.bits }
+func (c *mockSubPuzzleConfig) PuzzleSubPuzzles() int { return c.count }
+
+func Test_SubPuzzleScheme(t *testing.T) {
+	t.Run("issue, solve and verify ok", func(t *testing.T) {
+		server := NewSubPuzzleScheme(&mockSubPuzzleConfig{bits: 3, count: 16})
+		client := NewSubPuzzleScheme(nil)
+
+		issued, err := server.Issue("127.0.0.1:1234", time.Now())
+		require.NoError(t, err)
+
+		received, err := client.Parse(issued.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), received.Key())
+
+		ok, err := received.Verify()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, received.Solve(1000000, nil))
+
+		solved, err := server.Parse(received.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), solved.Key())
+		require.Equal(t, issued.IssueKey(), solved.IssueKey())
+		require.True(t, solved.EqualResource("127.0.0.1:1234"))
+		require.True(t, solved.IsActual(time.Now(), time.Minute))
+
+		ok, err = solved.Verify()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("tampered solution not verified", func(t *testing.T) {
+		p, err := NewSubPuzzleScheme(nil).Parse("8:2:1698953137:AQ==:1,2:resource")
+		require.NoError(t, err)
+
+		ok, err := p.Verify()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("precheck", func(t *testing.T) {
+		scheme := NewSubPuzzleScheme(nil)
+		now := time.Unix(1698953137, 0)
+
+		for _, tc := range []struct {
+			serialized string
+			err        error
+		}{
+			{serialized: "8:2:1698953137:AQIDBA==:1,2:resource", err: nil},
+			{serialized: "8:2:1698953137:AQIDBA==:1,2:" + strings.Repeat("a", 257), err: ErrResourceTooLong},
+			{serialized: "8:2:1698953137:AQIDBA==:1,2147483648:resource", err: ErrCounterTooLarge},
+			{serialized: "8:2:1698953138:AQIDBA==:1,2:resource", err: ErrDateInFuture},
+			{serialized: "8:2:1698953137:AQ==:1,2:resource", err: ErrIncorrectRand},
+		} {
+			p, err := scheme.Parse(tc.serialized)
+			require.NoError(t, err)
+			require.ErrorIs(t, p.Precheck(now), tc.err, tc.serialized)
+			require.Equal(t, 8, p.Difficulty())
+		}
+	})
+
+	t.Run("parse failed", func(t *testing.T) {
+		scheme := NewSubPuzzleScheme(nil)
+
+		for _, serialized := range []string{
+			"8:2:1698953137:AQ==:1:resource",
+			"8:3:1698953137:AQ==::resource",
+			"8:512:1698953137:AQ==::resource",
+			"0:2:1698953137:AQ==::resource",
+			"8:2:date:AQ==::resource",
+			"8:2:1698953137:AQ==:1,-2:resource",
+			"8:2:1698953137:AQ==",
+		} {
+			_, err := scheme.Parse(serialized)
+			require.ErrorIs(t, err, ErrIncorrectSubPuzzle, serialized)
+		}
+	})
+
+	t.Run("issue failed", func(t *testing.T) {
+		_, err := NewSubPuzzleScheme(&mockSubPuzzleConfig{bits: 1, count: 16}).Issue("resource", time.Now())
+		require.ErrorIs(t, err, ErrIncorrectSubPuzzles)
+
+		_, err = NewSubPuzzleScheme(&mockSubPuzzleConfig{bits: 5, count: 3}).Issue("resource", time.Now())
+		require.ErrorIs(t, err, ErrIncorrectSubPuzzles)
+	})
+
+	t.Run("sub-puzzle bits keep expected work", func(t *testing.T) {
+		require.Equal(t, 20, SubPuzzleBits(5, 1))
+		require.Equal(t, 16, SubPuzzleBits(5, 16))
+		require.Equal(t, 13, SubPuzzleBits(5, 128))
+	})
+}
@@ -0,0 +1,278 @@
+package puzzle
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+)
+
+// SchemeTimeLock - time-lock scheme id.
+const SchemeTimeLock = "timelock"
+
+// Time-lock puzzles limits.
+const (
+	MinTimeLockModulusBits = 256
+	MaxTimeLockModulusBits = 4096
+
+	timeLockParts      = 6
+	delimiterTimeLock  = ":"
+	timeLockNumberBase = 16
+	primesInModulus    = 2
+)
+
+// TimeLockConfig - time-lock puzzles config interface.
+// PuzzleTimeLockIterations - number of sequential squarings to solve puzzle.
+type TimeLockConfig interface {
+	PuzzleTimeLockIterations() int
+}
+
+// NewTimeLockScheme - create time-lock scheme with new secret RSA-style modulus of modulusBits bits.
+// Server keeps modulus factorization as a trapdoor to verify solutions cheaply.
+func NewTimeLockScheme(config TimeLockConfig, modulusBits int) (*TimeLockScheme, error) {
+	if modulusBits < MinTimeLockModulusBits || modulusBits > MaxTimeLockModulusBits {
+		return nil, ErrIncorrectTimeLockModulus
+	}
+
+	for {
+		p, err := rand.Prime(rand.Reader, modulusBits/primesInModulus)
+		if err != nil {
+			return nil, fmt.Errorf("generate prime: %w", err)
+		}
+
+		q, err := rand.Prime(rand.Reader, modulusBits-modulusBits/primesInModulus)
+		if err != nil {
+			return nil, fmt.Errorf("generate prime: %w", err)
+		}
+
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		one := big.NewInt(1)
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+
+		return &TimeLockScheme{
+			config:  config,
+			modulus: new(big.Int).Mul(p, q),
+			phi:     phi,
+		}, nil
+	}
+}
+
+// NewTimeLockClientScheme - create time-lock scheme to parse and solve puzzles on client side.
+func NewTimeLockClientScheme() *TimeLockScheme {
+	return &TimeLockScheme{}
+}
+
+// TimeLockScheme - puzzle is to compute x^(2^t) mod n by t sequential squarings.
+// Squarings could not be parallelized, so multi-core or GPU clients have no advantage.
+// Server knows phi(n) and verifies solution with one modular exponentiation x^(2^t mod phi(n)) mod n.
+type TimeLockScheme struct {
+	config  TimeLockConfig
+	modulus *big.Int
+	phi     *big.Int
+}
+
+// ID - scheme identifier.
+func (s *TimeLockScheme) ID() string {
+	return SchemeTimeLock
+}
+
+// Issue - issue new time-lock puzzle.
+func (s *TimeLockScheme) Issue(resource string, now time.Time) (Puzzle, error) {
+	if s.modulus == nil {
+		return nil, ErrIncorrectTimeLockModulus
+	}
+
+	iterations := s.config.PuzzleTimeLockIterations()
+	if iterations <= 0 {
+		return nil, ErrIncorrectTimeLockIterations
+	}
+
+	// base is random number in [2, n-2].
+	base, err := rand.Int(rand.Reader, new(big.Int).Sub(s.modulus, big.NewInt(3))) //nolint:gomnd // range.
+	if err != nil {
+		return nil, fmt.Errorf("get random error: %w", err)
+	}
+
+	return &timeLockPuzzle{
+		scheme:     s,
+		iterations: iterations,
+		date:       now.UTC().Truncate(time.Second),
+		base:       base.Add(base, big.NewInt(2)), //nolint:gomnd // range.
+		modulus:    s.modulus,
+		resource:   resource,
+	}, nil
+}
+
+// Parse - parse time-lock puzzle.
+// Format - iterations:date:base:modulus:solution:resource, numbers are in hex, solution is empty if not solved.
+// On server side modulus from puzzle is ignored, server verifies solution with own modulus.
+func (s *TimeLockScheme) Parse(serialized string) (Puzzle, error) {
+	var (
+		p   = &timeLockPuzzle{scheme: s}
+		ok  bool
+		err error
+	)
+
+	parts := strings.SplitN(serialized, delimiterTimeLock, timeLockParts)
+	if len(parts) != timeLockParts {
+		return nil, ErrIncorrectTimeLock
+	}
+
+	p.iterations, err = strconv.Atoi(parts[0])
+	if err != nil || p.iterations <= 0 {
+		return nil, ErrIncorrectTimeLock
+	}
+
+	date, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrIncorrectTimeLock
+	}
+
+	p.date = time.Unix(date, 0).UTC()
+
+	if p.modulus, ok = parseTimeLockNumber(parts[3]); !ok || p.modulus.BitLen() < MinTimeLockModulusBits {
+		return nil, ErrIncorrectTimeLock
+	}
+
+	if s.modulus != nil {
+		p.modulus = s.modulus
+	}
+
+	if p.base, ok = parseTimeLockNumber(parts[2]); !ok || p.base.Cmp(p.modulus) >= 0 {
+		return nil, ErrIncorrectTimeLock
+	}
+
+	if parts[4] != "" {
+		if p.solution, ok = parseTimeLockNumber(parts[4]); !ok || p.solution.Cmp(p.modulus) >= 0 {
+			return nil, ErrIncorrectTimeLock
+		}
+	}
+
+	p.resource = parts[5]
+
+	return p, nil
+}
+
+type timeLockPuzzle struct {
+	scheme     *TimeLockScheme
+	iterations int
+	date       time.Time
+	base       *big.Int
+	modulus    *big.Int
+	solution   *big.Int
+	resource   string
+}
+
+func (p *timeLockPuzzle) Key() string {
+	return strings.Join([]string{
+		strconv.Itoa(p.iterations),
+		strconv.FormatInt(p.date.Unix(), 10),
+		p.base.Text(timeLockNumberBase),
+		p.resource,
+	}, delimiterTimeLock)
+}
+
+func (p *timeLockPuzzle) IssueKey() string {
+	return strings.Join([]string{
+		strconv.FormatInt(p.date.Unix(), 10),
+		p.base.Text(timeLockNumberBase),
+		p.resource,
+	}, delimiterTimeLock)
+}
+
+func (p *timeLockPuzzle) Serialize() string {
+	var solution string
+	if p.solution != nil {
+		solution = p.solution.Text(timeLockNumberBase)
+	}
+
+	return strings.Join([]string{
+		strconv.Itoa(p.iterations),
+		strconv.FormatInt(p.date.Unix(), 10),
+		p.base.Text(timeLockNumberBase),
+		p.modulus.Text(timeLockNumberBase),
+		solution,
+		p.resource,
+	}, delimiterTimeLock)
+}
+
+func (p *timeLockPuzzle) EqualResource(resource string) bool {
+	return p.resource == resource
+}
+
+func (p *timeLockPuzzle) IsActual(now time.Time, ttl time.Duration) bool {
+	return p.date.Add(ttl).After(now)
+}
+
+func (p *timeLockPuzzle) Difficulty() int {
+	return p.iterations
+}
+
+func (p *timeLockPuzzle) Precheck(now time.Time) error {
+	switch {
+	case len(p.resource) > hashcash.MaxResourceLength:
+		return ErrResourceTooLong
+	case p.date.After(now):
+		return ErrDateInFuture
+	default:
+		return nil
+	}
+}
+
+// Solve - compute base^(2^iterations) mod modulus by sequential squarings.
+// Puzzle with more iterations than max attempts is not solved.
+// Every squaring is an attempt, so remaining attempts are exact.
+func (p *timeLockPuzzle) Solve(maxAttempts int, progress ProgressFunc) error {
+	if p.iterations > maxAttempts {
+		return ErrSolveMaxAttemptsExceeded
+	}
+
+	reporter := newProgressReporter(progress)
+
+	y := new(big.Int).Set(p.base)
+	for i := range p.iterations {
+		y.Mul(y, y).Mod(y, p.modulus)
+		reporter.report(i+1, float64(p.iterations-i-1))
+	}
+
+	p.solution = y
+	reporter.done(p.iterations)
+
+	return nil
+}
+
+// Verify - check solution using trapdoor: base^(2^iterations mod phi) mod modulus.
+func (p *timeLockPuzzle) Verify() (bool, error) {
+	if p.scheme.phi == nil {
+		return false, ErrIncorrectTimeLockModulus
+	}
+
+	if p.solution == nil {
+		return false, nil
+	}
+
+	exp := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(p.iterations)), p.scheme.phi) //nolint:gomnd // squaring.
+	expected := new(big.Int).Exp(p.base, exp, p.scheme.modulus)
+
+	return expected.Cmp(p.solution) == 0, nil
+}
+
+func parseTimeLockNumber(s string) (*big.Int, bool) {
+	if s == "" || len(s) > MaxTimeLockModulusBits/4 {
+		return nil, false
+	}
+
+	n, ok := new(big.Int).SetString(s, timeLockNumberBase)
+	if !ok || n.Sign() <= 0 {
+		return nil, false
+	}
+
+	return n, true
+}
@@ -0,0 +1,119 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockTimeLockConfig struct {
+	iterations int
+}
+
+func (c *mockTimeLockConfig) PuzzleTimeLockIterations() int { return c.iterations }
+
+func Test_TimeLockScheme(t *testing.T) {
+	server, err := NewTimeLockScheme(&mockTimeLockConfig{iterations: 1000}, MinTimeLockModulusBits)
+	require.NoError(t, err)
+
+	client := NewTimeLockClientScheme()
+
+	t.Run("issue, solve and verify ok", func(t *testing.T) {
+		issued, err := server.Issue("127.0.0.1:1234", time.Now())
+		require.NoError(t, err)
+
+		received, err := client.Parse(issued.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), received.Key())
+
+		require.NoError(t, received.Solve(1000000, nil))
+
+		solved, err := server.Parse(received.Serialize())
+		require.NoError(t, err)
+		require.Equal(t, issued.Key(), solved.Key())
+		require.Equal(t, issued.IssueKey(), solved.IssueKey())
+		require.True(t, solved.EqualResource("127.0.0.1:1234"))
+		require.True(t, solved.IsActual(time.Now(), time.Minute))
+
+		ok, err := solved.Verify()
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("solve max attempts exceeded", func(t *testing.T) {
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+
+		received, err := client.Parse(issued.Serialize())
+		require.NoError(t, err)
+		require.ErrorIs(t, received.Solve(999, nil), ErrSolveMaxAttemptsExceeded)
+	})
+
+	t.Run("unsolved or tampered solution not verified", func(t *testing.T) {
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+
+		unsolved, err := server.Parse(issued.Serialize())
+		require.NoError(t, err)
+
+		ok, err := unsolved.Verify()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		tampered, err := server.Parse(issued.Serialize()[:len(issued.Serialize())-len(":resource")] + "2:resource")
+		require.NoError(t, err)
+
+		ok, err = tampered.Verify()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("client verify not supported", func(t *testing.T) {
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+
+		received, err := client.Parse(issued.Serialize())
+		require.NoError(t, err)
+
+		_, err = received.Verify()
+		require.ErrorIs(t, err, ErrIncorrectTimeLockModulus)
+	})
+
+	t.Run("precheck", func(t *testing.T) {
+		issued, err := server.Issue("resource", time.Now())
+		require.NoError(t, err)
+		require.Equal(t, 1000, issued.Difficulty())
+		require.NoError(t, issued.Precheck(time.Now()))
+		require.ErrorIs(t, issued.Precheck(time.Now().Add(-time.Hour)), ErrDateInFuture)
+
+		long, err := server.Issue(strings.Repeat("a", 257), time.Now())
+		require.NoError(t, err)
+		require.ErrorIs(t, long.Precheck(time.Now()), ErrResourceTooLong)
+	})
+
+	t.Run("parse failed", func(t *testing.T) {
+		modulus := server.modulus.Text(timeLockNumberBase)
+
+		for _, serialized := range []string{
+			"",
+			"1000:1698953137:5:" + modulus + ":resource",
+			"0:1698953137:5:" + modulus + "::resource",
+			"x:1698953137:5:" + modulus + "::resource",
+			"1000:x:5:" + modulus + "::resource",
+			"1000:1698953137:x:" + modulus + "::resource",
+			"1000:1698953137:5:ff::resource",
+			"1000:1698953137:5:" + modulus + ":x:resource",
+			"1000:1698953137:" + modulus + ":" + modulus + "::resource",
+		} {
+			_, err := client.Parse(serialized)
+			require.ErrorIs(t, err, ErrIncorrectTimeLock, serialized)
+		}
+	})
+
+	t.Run("incorrect modulus size", func(t *testing.T) {
+		_, err := NewTimeLockScheme(&mockTimeLockConfig{iterations: 1}, MinTimeLockModulusBits-1)
+		require.ErrorIs(t, err, ErrIncorrectTimeLockModulus)
+	})
+}
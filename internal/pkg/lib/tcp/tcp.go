@@ -22,3 +22,8 @@ func (ec *ConnErrorChecker) IsTimeout(err error) bool {
 
 	return false
 }
+
+// IsClosed - define that tcp connection was closed.
+func (ec *ConnErrorChecker) IsClosed(err error) bool {
+	return errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+)
+
+// ErrorCode - stable code of error sent to client.
+// Codes don't depend on error texts, existing codes are never changed or reused.
+type ErrorCode int
+
+// Error codes.
+const (
+	CodeUnknown                    ErrorCode = 0
+	CodeIncorrectMessageFormat     ErrorCode = 1
+	CodeTimeoutExceeded            ErrorCode = 2
+	CodeUnknownCommand             ErrorCode = 3
+	CodeHashcashHeaderNotFound     ErrorCode = 4
+	CodeHashcashHeaderNotCorrect   ErrorCode = 5
+	CodeHashcashExpirationExceeded ErrorCode = 6
+	CodeInternalError              ErrorCode = 7
+	CodeServerShuttingDown         ErrorCode = 8
+	CodeServerBusy                 ErrorCode = 9
+	CodeUnsupportedPuzzleScheme    ErrorCode = 10
+	CodePuzzleDifficultyMismatch   ErrorCode = 11
+	CodePuzzleResourceTooLong      ErrorCode = 12
+	CodePuzzleCounterTooLarge      ErrorCode = 13
+	CodePuzzleDateInFuture         ErrorCode = 14
+	CodePuzzleRandNotCorrect       ErrorCode = 15
+	CodeUnsupportedProtocolVersion ErrorCode = 16
+	CodeUnsupportedCodec           ErrorCode = 17
+	CodeUnsupportedCommand         ErrorCode = 18
+	CodeProtocolViolation          ErrorCode = 19
+	CodeHandshakeNotFirst          ErrorCode = 20
+	CodePuzzleNotRequested         ErrorCode = 21
+	CodePuzzleLimitExceeded        ErrorCode = 22
+	CodeUnexpectedCommand          ErrorCode = 23
+	CodeUnsupportedCommandsLimit   ErrorCode = 24
+)
+
+// CodedError - error sent to client with its stable code.
+type CodedError struct {
+	Code ErrorCode
+	text string
+}
+
+// newCodedError - returns error with code and text.
+func newCodedError(code ErrorCode, text string) error {
+	return &CodedError{Code: code, text: text}
+}
+
+// Error - returns error text.
+func (e *CodedError) Error() string {
+	return e.text
+}
+
+// codeErrors - errors sent to client by code.
+var codeErrors = errorsByCode( //nolint:gochecknoglobals // constant.
+	ErrIncorrectMessageFormat,
+	ErrTimeoutExceeded,
+	ErrUnknownCommand,
+	ErrHashcashHeaderNotFound,
+	ErrHashcashHeaderNotCorrect,
+	ErrHashcashExpirationExceeded,
+	ErrInternalError,
+	ErrServerShuttingDown,
+	ErrServerBusy,
+	ErrUnsupportedPuzzleScheme,
+	ErrPuzzleDifficultyMismatch,
+	ErrPuzzleResourceTooLong,
+	ErrPuzzleCounterTooLarge,
+	ErrPuzzleDateInFuture,
+	ErrPuzzleRandNotCorrect,
+	ErrUnsupportedProtocolVersion,
+	ErrUnsupportedCodec,
+	ErrUnsupportedCommand,
+	ErrProtocolViolation,
+	ErrHandshakeNotFirst,
+	ErrPuzzleNotRequested,
+	ErrPuzzleLimitExceeded,
+	ErrUnexpectedCommand,
+	ErrUnsupportedCommandsLimit,
+)
+
+// errorsByCode - returns coded errors by their codes.
+func errorsByCode(errs ...error) map[ErrorCode]error {
+	byCode := make(map[ErrorCode]error, len(errs))
+	for _, err := range errs {
+		byCode[CodeOf(err)] = err
+	}
+
+	return byCode
+}
+
+// retryableCodes - codes of errors which could go away if request is repeated with a new puzzle.
+var retryableCodes = map[ErrorCode]bool{ //nolint:gochecknoglobals // constant.
+	CodeTimeoutExceeded:            true,
+	CodeHashcashHeaderNotFound:     true,
+	CodeHashcashExpirationExceeded: true,
+	CodeInternalError:              true,
+	CodeServerShuttingDown:         true,
+	CodeServerBusy:                 true,
+}
+
+// Err - returns error by code or nil for unknown code.
+func (c ErrorCode) Err() error {
+	return codeErrors[c]
+}
+
+// IsRetryable - reports whether error with this code could go away if request is repeated.
+func (c ErrorCode) IsRetryable() bool {
+	return retryableCodes[c]
+}
+
+// CodeOf - returns code of server error in err chain or CodeUnknown.
+func CodeOf(err error) ErrorCode {
+	var codedErr *CodedError
+	if errors.As(err, &codedErr) {
+		return codedErr.Code
+	}
+
+	return CodeUnknown
+}
+
+// IsRetryable - reports whether request failed with server error err could succeed if repeated.
+func IsRetryable(err error) bool {
+	return CodeOf(err).IsRetryable()
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+)
+
+func Test_ErrorCode(t *testing.T) {
+	t.Run("codes and texts are unique", func(t *testing.T) {
+		require.Len(t, codeErrors, int(CodeUnsupportedCommandsLimit))
+
+		texts := make(map[string]ErrorCode)
+		for code, err := range codeErrors {
+			require.Equal(t, code, CodeOf(err))
+			require.Equal(t, err, code.Err())
+			require.NotContains(t, texts, err.Error())
+			texts[err.Error()] = code
+		}
+
+		require.Nil(t, CodeUnknown.Err())
+		require.Equal(t, CodeUnknown, CodeOf(errors.New(ErrServerBusy.Error())))
+	})
+
+	t.Run("server error restored from message", func(t *testing.T) {
+		for code, codeErr := range codeErrors {
+			err := parseErrorMessage(errorMessage(codeErr))
+
+			require.ErrorIs(t, err, codeErr)
+			require.Equal(t, code, CodeOf(err))
+			require.Equal(t, code.IsRetryable(), IsRetryable(fmt.Errorf("request: %w", err)))
+		}
+	})
+
+	t.Run("error message format", func(t *testing.T) {
+		require.Equal(t, "0:6;0;hashcash expiration exceeded\n", errorMessage(ErrHashcashExpirationExceeded).String())
+		require.Equal(t, "0:9;1500;server busy, retry later\n",
+			errorMessage(&RetryAfterError{Err: ErrServerBusy, RetryAfter: 1500 * time.Millisecond}).String())
+		require.Equal(t, "0:0;0;something new\n", errorMessage(errors.New("something new")).String())
+	})
+
+	t.Run("busy error with retry-after hint", func(t *testing.T) {
+		err := parseErrorMessage(errorMessage(&RetryAfterError{Err: ErrServerBusy, RetryAfter: time.Second}))
+
+		var retryErr *RetryAfterError
+		require.ErrorAs(t, err, &retryErr)
+		require.Equal(t, time.Second, retryErr.RetryAfterHint())
+		require.ErrorIs(t, err, ErrServerBusy)
+		require.True(t, IsRetryable(err))
+	})
+
+	t.Run("code unknown to client", func(t *testing.T) {
+		err := parseErrorMessage(message.Message{Command: message.CommandError, Payload: "100;0;new error; details"})
+
+		var serverErr *ServerError
+		require.ErrorAs(t, err, &serverErr)
+		require.Equal(t, &ServerError{Code: 100, Message: "new error; details"}, serverErr)
+		require.False(t, IsRetryable(err))
+	})
+
+	t.Run("legacy error messages", func(t *testing.T) {
+		for code, codeErr := range codeErrors {
+			err := parseErrorMessage(message.Message{Command: message.CommandError, Payload: codeErr.Error()})
+
+			require.ErrorIs(t, err, codeErr)
+			require.Equal(t, code, CodeOf(err))
+		}
+
+		err := parseErrorMessage(message.Message{Command: message.CommandError,
+			Payload: "server busy, retry later; retry_after_ms=1000"})
+
+		var retryErr *RetryAfterError
+		require.ErrorAs(t, err, &retryErr)
+		require.Equal(t, time.Second, retryErr.RetryAfterHint())
+		require.ErrorIs(t, err, ErrServerBusy)
+	})
+
+	t.Run("unknown legacy server error", func(t *testing.T) {
+		for _, payload := range []string{"something new", "1;x;text", "server busy, retry later; retry_after_ms=x"} {
+			err := parseErrorMessage(message.Message{Command: message.CommandError, Payload: payload})
+
+			require.Equal(t, CodeUnknown, CodeOf(err))
+			require.False(t, IsRetryable(err))
+			require.EqualError(t, err, "checkResMessage: "+payload)
+		}
+	})
+}
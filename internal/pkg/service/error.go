@@ -2,28 +2,203 @@ package service
 
 import (
 	"errors"
-	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 )
 
 var (
-	ErrIncorrectMessageFormat     = errors.New("incorrect message format")
-	ErrTimeoutExceeded            = errors.New("timeout exceeded")
-	ErrUnknownCommand             = errors.New("unknown command")
-	ErrHashcashHeaderNotFound     = errors.New("hashcash header not found")
-	ErrHashcashHeaderNotCorrect   = errors.New("hashcash header not correct")
-	ErrHashcashExpirationExceeded = errors.New("hashcash expiration exceeded")
-	ErrInternalError              = errors.New("internal error")
+	ErrIncorrectMessageFormat     = newCodedError(CodeIncorrectMessageFormat, "incorrect message format")
+	ErrTimeoutExceeded            = newCodedError(CodeTimeoutExceeded, "timeout exceeded")
+	ErrUnknownCommand             = newCodedError(CodeUnknownCommand, "unknown command")
+	ErrHashcashHeaderNotFound     = newCodedError(CodeHashcashHeaderNotFound, "hashcash header not found")
+	ErrHashcashHeaderNotCorrect   = newCodedError(CodeHashcashHeaderNotCorrect, "hashcash header not correct")
+	ErrHashcashExpirationExceeded = newCodedError(CodeHashcashExpirationExceeded, "hashcash expiration exceeded")
+	ErrInternalError              = newCodedError(CodeInternalError, "internal error")
 	ErrResponseCommandNotcorrect  = errors.New("response command is not correct")
+	ErrServerShuttingDown         = newCodedError(CodeServerShuttingDown, "server shutting down")
+	ErrServerBusy                 = newCodedError(CodeServerBusy, "server busy, retry later")
+	ErrUnsupportedPuzzleScheme    = newCodedError(CodeUnsupportedPuzzleScheme, "unsupported puzzle scheme")
+	ErrPuzzleDifficultyMismatch   = newCodedError(CodePuzzleDifficultyMismatch, "puzzle difficulty doesn't match issued")
+	ErrPuzzleResourceTooLong      = newCodedError(CodePuzzleResourceTooLong, "puzzle resource too long")
+	ErrPuzzleCounterTooLarge      = newCodedError(CodePuzzleCounterTooLarge, "puzzle counter too large")
+	ErrPuzzleDateInFuture         = newCodedError(CodePuzzleDateInFuture, "puzzle date in future")
+	ErrPuzzleRandNotCorrect       = newCodedError(CodePuzzleRandNotCorrect, "puzzle random field not correct")
+	ErrUnsupportedProtocolVersion = newCodedError(CodeUnsupportedProtocolVersion, "unsupported protocol version")
+	ErrUnsupportedCodec           = newCodedError(CodeUnsupportedCodec, "unsupported codec")
+	ErrUnsupportedCommand         = newCodedError(CodeUnsupportedCommand, "unsupported command")
+	ErrProtocolViolation          = newCodedError(CodeProtocolViolation, "protocol violation, command isn't allowed from client")
+	ErrHandshakeNotFirst          = newCodedError(CodeHandshakeNotFirst, "handshake is allowed only as the first message")
+	ErrPuzzleNotRequested         = newCodedError(CodePuzzleNotRequested, "resource requested without puzzle")
+	ErrPuzzleLimitExceeded        = newCodedError(CodePuzzleLimitExceeded, "puzzles per connection limit exceeded")
+	ErrUnexpectedCommand          = newCodedError(CodeUnexpectedCommand, "command isn't expected in connection state")
+	ErrUnsupportedCommandsLimit   = newCodedError(CodeUnsupportedCommandsLimit, "unsupported commands limit exceeded")
+	ErrIncorrectWelcome           = errors.New("incorrect welcome, server picked unsupported value")
 	ErrCheckResMessage            = func(resMsg message.Message) error { //nolint:gochecknoglobals // pure functions.
-		return fmt.Errorf("checkResMessage: %s", resMsg.Payload) //nolint:goerr113 // error message.
+		return parseErrorMessage(resMsg)
 	}
 )
 
+// ServerError - error message received from server.
+// Code and Err - code and error restored from message, CodeUnknown and nil for unknown message.
+type ServerError struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+// Error - format server error.
+func (e *ServerError) Error() string {
+	return "checkResMessage: " + e.Message
+}
+
+// Unwrap - returns restored error.
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfterSeparator - divides error text and retry-after hint in legacy error payload.
+const retryAfterSeparator = "; retry_after_ms="
+
+// Error payload has "code;retry_after_ms;message" format, e.g. "9;1000;server busy, retry later".
+const (
+	errorFieldSeparator = ";"
+	errorFields         = 3
+)
+
+// RetryAfterError - error with a hint when request could be retried.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+// Error - format error with retry-after hint.
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error() + retryAfterSeparator + strconv.FormatInt(e.RetryAfter.Milliseconds(), 10)
+}
+
+// Unwrap - returns original error.
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterHint - returns duration to wait before retry.
+func (e *RetryAfterError) RetryAfterHint() time.Duration {
+	return e.RetryAfter
+}
+
+// precheckError - returns client error for puzzle precheck error.
+func precheckError(err error) error {
+	switch {
+	case errors.Is(err, puzzle.ErrResourceTooLong):
+		return ErrPuzzleResourceTooLong
+	case errors.Is(err, puzzle.ErrCounterTooLarge):
+		return ErrPuzzleCounterTooLarge
+	case errors.Is(err, puzzle.ErrDateInFuture):
+		return ErrPuzzleDateInFuture
+	case errors.Is(err, puzzle.ErrIncorrectRand):
+		return ErrPuzzleRandNotCorrect
+	default:
+		return ErrHashcashHeaderNotCorrect
+	}
+}
+
+// errorMessage - returns error message with error code, retry-after hint and error text.
 func errorMessage(err error) message.Message {
+	code := CodeOf(err)
+
+	text := err.Error()
+	if codeErr := code.Err(); codeErr != nil {
+		text = codeErr.Error()
+	}
+
+	var (
+		retryErr   *RetryAfterError
+		retryAfter int64
+	)
+
+	if errors.As(err, &retryErr) {
+		retryAfter = retryErr.RetryAfter.Milliseconds()
+	}
+
 	return message.Message{
 		Command: message.CommandError,
-		Payload: err.Error(),
+		Payload: strconv.Itoa(int(code)) + errorFieldSeparator + strconv.FormatInt(retryAfter, 10) +
+			errorFieldSeparator + text,
 	}
 }
+
+// parseErrorMessage - restore error from error message payload.
+// Returns *ServerError or *RetryAfterError wrapping *ServerError if server sent retry-after hint.
+// Legacy payload without code is restored by error text.
+func parseErrorMessage(resMsg message.Message) error {
+	serverErr, retryAfter, ok := parseCodedError(resMsg.Payload)
+	if !ok {
+		serverErr, retryAfter = parseLegacyError(resMsg.Payload)
+	}
+
+	if retryAfter <= 0 {
+		return serverErr
+	}
+
+	return &RetryAfterError{
+		Err:        serverErr,
+		RetryAfter: retryAfter,
+	}
+}
+
+// parseCodedError - parse "code;retry_after_ms;message" payload.
+func parseCodedError(payload string) (serverErr *ServerError, retryAfter time.Duration, ok bool) {
+	fields := strings.SplitN(payload, errorFieldSeparator, errorFields)
+	if len(fields) != errorFields {
+		return nil, 0, false
+	}
+
+	code, err := strconv.Atoi(fields[0])
+	if err != nil || code < 0 {
+		return nil, 0, false
+	}
+
+	ms, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || ms < 0 {
+		return nil, 0, false
+	}
+
+	serverErr = &ServerError{
+		Code:    ErrorCode(code),
+		Message: fields[2],
+		Err:     ErrorCode(code).Err(),
+	}
+
+	return serverErr, time.Duration(ms) * time.Millisecond, true
+}
+
+// parseLegacyError - parse error text payload, busy error could have retry-after hint.
+func parseLegacyError(payload string) (serverErr *ServerError, retryAfter time.Duration) {
+	text, rawHint, ok := strings.Cut(payload, retryAfterSeparator)
+	if !ok || text != ErrServerBusy.Error() {
+		return legacyError(payload), 0
+	}
+
+	ms, err := strconv.ParseInt(rawHint, 10, 64)
+	if err != nil || ms < 0 {
+		return &ServerError{Code: CodeUnknown, Message: payload}, 0
+	}
+
+	return &ServerError{Code: CodeServerBusy, Message: text, Err: ErrServerBusy}, time.Duration(ms) * time.Millisecond
+}
+
+// legacyError - restore error from text payload of older server, which sends error text without code,
+// so text is matched against texts of known errors.
+func legacyError(text string) *ServerError {
+	for code, codeErr := range codeErrors {
+		if codeErr.Error() == text {
+			return &ServerError{Code: code, Message: text, Err: codeErr}
+		}
+	}
+
+	return &ServerError{Code: CodeUnknown, Message: text}
+}
@@ -1,11 +1,16 @@
 package service
 
-import "time"
+import (
+	"time"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+)
 
 // PuzzleCache - puzzle cache interface.
+// Value is issued puzzle difficulty.
 type PuzzleCache interface {
-	AddWithExp(k string, v struct{}, exp time.Time)
-	Get(k string) (v struct{}, ok bool)
+	AddWithExp(k string, v int, exp time.Time)
+	Get(k string) (v int, ok bool)
 	Delete(k string)
 }
 
@@ -21,18 +26,34 @@ type Logger interface {
 	Error(msg string, args ...any)
 }
 
+// Penalties - client violations counter interface.
+type Penalties interface {
+	Violation(addr, reason string) (count int, banned bool)
+}
+
 // ErrorChecker - error checker interface.
 type ErrorChecker interface {
 	IsTimeout(err error) bool
+	IsClosed(err error) bool
 }
 
 // ServerConfig - server config interface.
+// PuzzleClockSkew - tolerated server clock adjustment between puzzle issue and verification.
 type ServerConfig interface {
 	PuzzleTTL() time.Duration
-	PuzzleZeroBits() int
+	PuzzleClockSkew() time.Duration
+	PuzzleVerifyConcurrency() int
+	MaxPuzzlesPerConnection() int
 }
 
 // ClientConfig - client config interface.
 type ClientConfig interface {
 	PuzzleComputeMaxAttempts() int
 }
+
+// PuzzleSchemes - puzzle schemes registry interface.
+type PuzzleSchemes interface {
+	Get(id string) (puzzle.Scheme, bool)
+	IDs() []string
+	Negotiate(peerIDs []string) (puzzle.Scheme, error)
+}
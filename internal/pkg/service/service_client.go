@@ -2,30 +2,43 @@ package service
 
 import (
 	"bufio"
+	"errors"
 	"io"
+	"slices"
 
-	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 )
 
 // Opts - options to create new cache instance.
+// Progress - optional callback to receive puzzle solving progress.
+// Handshake - start with hello handshake, servers without handshake support reject it.
 type ClientOpts struct {
-	Logger Logger
-	Config ClientConfig
+	Logger    Logger
+	Config    ClientConfig
+	Schemes   PuzzleSchemes
+	Progress  puzzle.ProgressFunc
+	Handshake bool
 }
 
 // NewClient - create new client-side service.
 func NewClient(opts ClientOpts) *Client {
 	return &Client{
-		logger: opts.Logger,
-		config: opts.Config,
+		logger:    opts.Logger,
+		config:    opts.Config,
+		schemes:   opts.Schemes,
+		progress:  opts.Progress,
+		handshake: opts.Handshake,
 	}
 }
 
 // Client - client-side service.
 type Client struct {
-	logger Logger
-	config ClientConfig
+	logger    Logger
+	config    ClientConfig
+	schemes   PuzzleSchemes
+	progress  puzzle.ProgressFunc
+	handshake bool
 }
 
 // RequestResource - request server resource.
@@ -36,39 +49,56 @@ func (c *Client) RequestResource(clientID string, reader io.ReadWriter) (resourc
 
 	puzzleReqMsg := message.Message{
 		Command: message.CommandRequestPuzzle,
+		Payload: puzzle.JoinIDs(c.schemes.IDs()),
 	}
 
-	c.logger.Info("requesting puzzle", "clientID", clientID)
+	if c.handshake {
+		var welcome message.Hello
 
-	puzzle, err := c.request(clientID, puzzleReqMsg, reader)
+		if welcome, err = c.hello(clientID, reader); err != nil {
+			c.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
+
+			return
+		}
+
+		puzzleReqMsg.Payload = puzzle.JoinIDs(welcome.Schemes)
+	}
+
+	c.logger.Info("requesting puzzle", "clientID", clientID, "schemes", puzzleReqMsg.Payload)
+
+	envelope, err := c.request(clientID, puzzleReqMsg, reader)
 	if err != nil {
 		c.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
 
 		return
 	}
 
-	c.logger.Info("puzzle received", "clientID", clientID, "puzzle", puzzle)
+	c.logger.Info("puzzle received", "clientID", clientID, "puzzle", envelope)
 
-	mainHashcash, err := hashcash.ParseHeader(puzzle)
+	scheme, mainPuzzle, legacy, err := c.parsePuzzle(envelope)
 	if err != nil {
 		c.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
 
 		return
 	}
 
-	c.logger.Info("solving puzzle", "clientID", clientID)
+	c.logger.Info("solving puzzle", "clientID", clientID, "scheme", scheme.ID())
 
-	if err = mainHashcash.Compute(c.config.PuzzleComputeMaxAttempts()); err != nil {
+	if err = mainPuzzle.Solve(c.config.PuzzleComputeMaxAttempts(), c.progress); err != nil {
 		c.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
 
 		return
 	}
 
-	c.logger.Info("puzzle solved", "clientID", clientID, "counter", mainHashcash.Counter())
+	c.logger.Info("puzzle solved", "clientID", clientID, "solution", mainPuzzle.Serialize())
 
 	resourceReqMsg := message.Message{
 		Command: message.CommandRequestResource,
-		Payload: string(mainHashcash.Header()),
+		Payload: puzzle.Wrap(scheme.ID(), mainPuzzle),
+	}
+
+	if legacy {
+		resourceReqMsg.Payload = mainPuzzle.Serialize()
 	}
 
 	c.logger.Info("requesting resource", "clientID", clientID)
@@ -85,6 +115,63 @@ func (c *Client) RequestResource(clientID string, reader io.ReadWriter) (resourc
 	return
 }
 
+// hello - send supported versions, schemes and codecs and returns values picked by server.
+func (c *Client) hello(clientID string, reader io.ReadWriter) (message.Hello, error) {
+	hello := message.Hello{
+		Versions: message.Versions(),
+		Schemes:  c.schemes.IDs(),
+		Codecs:   message.Codecs(),
+	}
+
+	c.logger.Info("handshake", "clientID", clientID, "hello", hello.String())
+
+	payload, err := c.request(clientID, message.Message{Command: message.CommandHello, Payload: hello.String()}, reader)
+	if err != nil {
+		return message.Hello{}, err
+	}
+
+	welcome, err := message.ParseHello(payload)
+	if err != nil {
+		return message.Hello{}, err //nolint:wrapcheck // message error.
+	}
+
+	if len(welcome.Versions) != 1 || !slices.Contains(hello.Versions, welcome.Versions[0]) ||
+		len(welcome.Codecs) != 1 || !slices.Contains(hello.Codecs, welcome.Codecs[0]) ||
+		len(welcome.Schemes) != 1 || !slices.Contains(hello.Schemes, welcome.Schemes[0]) {
+		return message.Hello{}, ErrIncorrectWelcome
+	}
+
+	c.logger.Info("handshake completed", "clientID", clientID, "version", welcome.Versions[0],
+		"scheme", welcome.Schemes[0], "codec", welcome.Codecs[0])
+
+	return welcome, nil
+}
+
+// parsePuzzle - returns scheme and puzzle from envelope.
+// Legacy servers send hashcash header without scheme id, legacy is true for such puzzle.
+func (c *Client) parsePuzzle(envelope string) (scheme puzzle.Scheme, mainPuzzle puzzle.Puzzle, legacy bool, err error) {
+	id, serialized, err := puzzle.Unwrap(envelope)
+	if errors.Is(err, puzzle.ErrIncorrectEnvelope) {
+		id, serialized, legacy, err = puzzle.SchemeHashcash, envelope, true, nil
+	}
+
+	if err != nil {
+		return nil, nil, false, err //nolint:wrapcheck // puzzle error.
+	}
+
+	scheme, ok := c.schemes.Get(id)
+	if !ok {
+		return nil, nil, false, puzzle.ErrUnknownScheme
+	}
+
+	mainPuzzle, err = scheme.Parse(serialized)
+	if err != nil {
+		return nil, nil, false, err //nolint:wrapcheck // puzzle error.
+	}
+
+	return scheme, mainPuzzle, legacy, nil
+}
+
 func (c *Client) request(clientID string, msg message.Message, reader io.ReadWriter) (payload string, err error) {
 	if err = c.writeMsg(clientID, msg, reader); err != nil {
 		return
@@ -119,7 +206,7 @@ func (c *Client) writeMsg(clientID string, msg message.Message, w io.Writer) (er
 
 func (c *Client) checkResMessage(reqCmd message.Command, resMsg message.Message) (err error) {
 	if resMsg.Command == message.CommandError {
-		return ErrCheckResMessage(resMsg)
+		return parseErrorMessage(resMsg)
 	}
 
 	if reqCmd == message.CommandRequestPuzzle && resMsg.Command != message.CommandResponsePuzzle {
@@ -130,5 +217,9 @@ func (c *Client) checkResMessage(reqCmd message.Command, resMsg message.Message)
 		return ErrResponseCommandNotcorrect
 	}
 
+	if reqCmd == message.CommandHello && resMsg.Command != message.CommandWelcome {
+		return ErrResponseCommandNotcorrect
+	}
+
 	return
 }
@@ -3,33 +3,53 @@ package service
 import (
 	"bufio"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"time"
 
-	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
 	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
 )
 
 // Opts - options to create new cache instance.
+// Penalties - optional, client violations are counted and could lead to ban.
+// Clock - optional, system clock is used by default.
 type ServerOpts struct {
 	Logger        Logger
 	Config        ServerConfig
 	PuzzleCache   PuzzleCache
 	ResourceCache ResourceCache
 	ErrorChecker  ErrorChecker
+	Schemes       PuzzleSchemes
+	Penalties     Penalties
+	Clock         clock.Clock
 }
 
 // NewServer - create new server-side service.
 func NewServer(opts *ServerOpts) *Server {
-	return &Server{
+	s := &Server{
 		logger:        opts.Logger,
 		config:        opts.Config,
 		puzzleCache:   opts.PuzzleCache,
 		resourceCache: opts.ResourceCache,
 		errorChecker:  opts.ErrorChecker,
+		schemes:       opts.Schemes,
+		penalties:     opts.Penalties,
+		clock:         clock.OrReal(opts.Clock),
+		commands:      message.DefaultRegistry(),
+		verifyLimiter: make(chan struct{}, max(opts.Config.PuzzleVerifyConcurrency(), 1)),
 	}
+
+	s.handlers = map[message.Command]handlerFunc{
+		message.CommandHello:           s.handleHello,
+		message.CommandRequestPuzzle:   s.handleRequestPuzzle,
+		message.CommandRequestResource: s.handleRequestResource,
+	}
+
+	return s
 }
 
 // Server - server-side service.
@@ -39,17 +59,66 @@ type Server struct {
 	puzzleCache   PuzzleCache
 	resourceCache ResourceCache
 	errorChecker  ErrorChecker
+	schemes       PuzzleSchemes
+	penalties     Penalties
+	clock         clock.Clock
+	commands      *message.Registry
+	handlers      map[message.Command]handlerFunc
+
+	// verifyLimiter - bounds number of concurrent solution verifications,
+	// memory-hard algorithms use a lot of memory for every verification.
+	verifyLimiter chan struct{}
+}
+
+// maxUnsupportedCommands - unsupported commands tolerated per connection,
+// the next one is counted as violation and closes connection.
+const maxUnsupportedCommands = 3
+
+// connection - state of client connection passed to command handlers.
+// scheme and codec - picked by handshake, nil and empty without handshake.
+// unsupported - number of received unsupported commands.
+type connection struct {
+	clientID    string
+	rw          io.ReadWriter
+	r           *bufio.Reader
+	state       stateMachine
+	scheme      puzzle.Scheme
+	codec       string
+	unsupported int
+}
+
+func (s *Server) newConnection(clientID string, rw io.ReadWriter) *connection {
+	return &connection{
+		clientID: clientID,
+		rw:       rw,
+		r:        bufio.NewReader(rw),
+		state:    stateMachine{maxPuzzles: s.config.MaxPuzzlesPerConnection()},
+	}
 }
 
+// handlerFunc - command handler, returns false to close connection.
+type handlerFunc func(conn *connection, msg message.Message) bool
+
 // HandleMessages - handle client messages.
+// Messages are dispatched to handlers of registered commands, unsupported commands are rejected
+// without closing connection up to maxUnsupportedCommands. Commands must follow the connection state machine:
+// optional handshake, one or more puzzle requests up to the limit, resource request.
 func (s *Server) HandleMessages(clientID string, reader io.ReadWriter) {
 	const operationName = "service.Server.HandleMessages"
 
 	s.logger.Info("connected new client", "clientID", clientID)
 
+	conn := s.newConnection(clientID, reader)
+
 	for {
-		rawMsg, err := bufio.NewReader(reader).ReadString(message.DelimiterMessage)
+		rawMsg, err := conn.r.ReadString(message.DelimiterMessage)
 		if err != nil {
+			if s.errorChecker.IsClosed(err) {
+				s.logger.Info("connection closed", "clientID", clientID)
+
+				return
+			}
+
 			clientErr := ErrInternalError
 			if s.errorChecker.IsTimeout(err) {
 				clientErr = ErrTimeoutExceeded
@@ -63,7 +132,28 @@ func (s *Server) HandleMessages(clientID string, reader io.ReadWriter) {
 			return
 		}
 
-		msg, err := message.ParseMessage(rawMsg)
+		msg, err := s.commands.ParseDirection(rawMsg, message.DirectionClientToServer)
+		if errors.Is(err, message.ErrWrongDirection) {
+			s.violation(clientID, ErrProtocolViolation, "command", msg.Command)
+			s.writeError(clientID, ErrProtocolViolation, reader)
+
+			return
+		}
+
+		if errors.Is(err, message.ErrUnsupportedCommand) {
+			if conn.unsupported++; conn.unsupported > maxUnsupportedCommands {
+				s.violation(clientID, ErrUnsupportedCommandsLimit, "command", msg.Command)
+				s.writeError(clientID, ErrUnsupportedCommandsLimit, reader)
+
+				return
+			}
+
+			s.logger.Info(ErrUnsupportedCommand.Error(), "clientID", clientID, "command", msg.Command)
+			s.writeError(clientID, ErrUnsupportedCommand, reader)
+
+			continue
+		}
+
 		if err != nil {
 			s.logger.Info(ErrIncorrectMessageFormat.Error(), "clientID", clientID, "message", rawMsg)
 			s.writeError(clientID, ErrIncorrectMessageFormat, reader)
@@ -71,31 +161,137 @@ func (s *Server) HandleMessages(clientID string, reader io.ReadWriter) {
 			return
 		}
 
-		switch msg.Command {
-		case message.CommandRequestPuzzle:
-			s.responsePuzzle(clientID, msg.Payload, reader)
-		case message.CommandRequestResource:
-			s.responseResource(clientID, msg.Payload, reader)
+		handler, ok := s.handlers[msg.Command]
+		if !ok {
+			s.writeError(clientID, ErrIncorrectMessageFormat, reader)
 
 			return
-		case message.CommandError, message.CommandResponsePuzzle, message.CommandResponseResource:
-			s.responseResource(clientID, msg.Payload, reader)
+		}
+
+		if err = conn.state.transition(msg.Command); err != nil {
+			s.violation(clientID, err, "command", msg.Command, "state", conn.state.state.String())
+			s.writeError(clientID, err, reader)
 
 			return
-		default:
-			s.writeError(clientID, ErrIncorrectMessageFormat, reader)
+		}
 
+		if !handler(conn, msg) {
 			return
 		}
 	}
 }
 
-func (s *Server) responsePuzzle(clientID, _ string, w io.Writer) {
+// violation - log client violation and count it in penalties.
+func (s *Server) violation(clientID string, violation error, args ...any) {
+	args = append(args, "clientID", clientID)
+
+	if s.penalties != nil {
+		count, banned := s.penalties.Violation(clientID, violation.Error())
+		args = append(args, "violations", count, "banned", banned)
+	}
+
+	s.logger.Info(violation.Error(), args...)
+}
+
+func (s *Server) handleHello(conn *connection, msg message.Message) bool {
+	return s.handshake(conn, msg.Payload)
+}
+
+func (s *Server) handleRequestPuzzle(conn *connection, msg message.Message) bool {
+	s.responsePuzzle(conn, msg.Payload)
+
+	return true
+}
+
+func (s *Server) handleRequestResource(conn *connection, msg message.Message) bool {
+	s.responseResource(conn, msg.Payload)
+
+	return false
+}
+
+// HandleShutdown - notify client that server is shutting down.
+func (s *Server) HandleShutdown(clientID string, w io.Writer) {
+	s.logger.Info(ErrServerShuttingDown.Error(), "clientID", clientID)
+	s.writeError(clientID, ErrServerShuttingDown, w)
+}
+
+// HandleBusy - notify client that server is busy and request could be retried later.
+func (s *Server) HandleBusy(clientID string, w io.Writer, retryAfter time.Duration) {
+	s.logger.Info(ErrServerBusy.Error(), "clientID", clientID, "retryAfter", retryAfter)
+	s.writeError(clientID, &RetryAfterError{Err: ErrServerBusy, RetryAfter: retryAfter}, w)
+}
+
+// handshake - answer to client hello with picked protocol version, puzzle scheme and codec.
+// Picked scheme and codec are kept in connection for the rest of it.
+// Returns false if there is no common version, scheme or codec.
+func (s *Server) handshake(conn *connection, payload string) bool {
+	clientID, w := conn.clientID, conn.rw
+
+	s.logger.Info("handshake", "clientID", clientID, "hello", payload)
+
+	hello, err := message.ParseHello(payload)
+	if err != nil {
+		s.logger.Info(ErrIncorrectMessageFormat.Error(), "clientID", clientID, "hello", payload)
+		s.writeError(clientID, ErrIncorrectMessageFormat, w)
+
+		return false
+	}
+
+	version, ok := message.Negotiate(message.Versions(), hello.Versions)
+	if !ok {
+		s.logger.Info(ErrUnsupportedProtocolVersion.Error(), "clientID", clientID, "versions", hello.Versions)
+		s.writeError(clientID, ErrUnsupportedProtocolVersion, w)
+
+		return false
+	}
+
+	codec, ok := message.Negotiate(message.Codecs(), hello.Codecs)
+	if !ok {
+		s.logger.Info(ErrUnsupportedCodec.Error(), "clientID", clientID, "codecs", hello.Codecs)
+		s.writeError(clientID, ErrUnsupportedCodec, w)
+
+		return false
+	}
+
+	scheme, err := s.schemes.Negotiate(hello.Schemes)
+	if err != nil {
+		s.logger.Info(ErrUnsupportedPuzzleScheme.Error(), "clientID", clientID, "schemes", hello.Schemes)
+		s.writeError(clientID, ErrUnsupportedPuzzleScheme, w)
+
+		return false
+	}
+
+	welcome := message.Hello{
+		Versions: []string{version},
+		Schemes:  []string{scheme.ID()},
+		Codecs:   []string{codec},
+	}
+
+	conn.scheme, conn.codec = scheme, codec
+
+	s.writeMsg(clientID, message.Message{Command: message.CommandWelcome, Payload: welcome.String()}, w)
+
+	return true
+}
+
+func (s *Server) responsePuzzle(conn *connection, payload string) {
 	const operationName = "service.Server.responsePuzzle"
 
-	s.logger.Info("requested new puzzle", "clientID", clientID)
+	clientID, w := conn.clientID, conn.rw
 
-	mainHashcash, err := hashcash.New(s.config.PuzzleZeroBits(), clientID)
+	s.logger.Info("requested new puzzle", "clientID", clientID, "schemes", payload)
+
+	scheme, legacy, err := s.puzzleScheme(conn, payload)
+	if err != nil {
+		s.logger.Info(ErrUnsupportedPuzzleScheme.Error(), "clientID", clientID, "schemes", payload)
+		s.writeError(clientID, ErrUnsupportedPuzzleScheme, w)
+
+		return
+	}
+
+	now := s.clock.Now()
+
+	mainPuzzle, err := scheme.Issue(clientID, now)
 	if err != nil {
 		s.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
 		s.writeError(clientID, ErrInternalError, w)
@@ -103,24 +299,66 @@ func (s *Server) responsePuzzle(clientID, _ string, w io.Writer) {
 		return
 	}
 
-	exp := time.Now().Add(s.config.PuzzleTTL())
-	s.puzzleCache.AddWithExp(mainHashcash.Key(), struct{}{}, exp)
+	// Puzzle is kept for clock skew longer than its ttl, so expiration tolerance applies to cached puzzles too.
+	exp := now.Add(s.config.PuzzleTTL() + s.config.PuzzleClockSkew())
+	s.puzzleCache.AddWithExp(puzzleCacheKey(scheme, mainPuzzle), mainPuzzle.Difficulty(), exp)
 
 	msg := message.Message{
 		Command: message.CommandResponsePuzzle,
-		Payload: string(mainHashcash.Header()),
+		Payload: puzzle.Wrap(scheme.ID(), mainPuzzle),
+	}
+
+	if legacy {
+		msg.Payload = mainPuzzle.Serialize()
 	}
 
 	s.writeMsg(clientID, msg, w)
-	s.logger.Info("puzzle sent", "clientID", clientID, "puzzle", msg.Payload)
+	s.logger.Info("puzzle sent", "clientID", clientID, "puzzle", msg.Payload, "codec", conn.codec)
 }
 
-func (s *Server) responseResource(clientID, payload string, w io.Writer) {
+// puzzleScheme - returns scheme of puzzle request payload with list of supported schemes.
+// After handshake only picked scheme could be requested.
+// Legacy clients don't send supported schemes and receive hashcash puzzle without scheme id.
+func (s *Server) puzzleScheme(conn *connection, payload string) (scheme puzzle.Scheme, legacy bool, err error) {
+	if conn.scheme != nil {
+		if payload != "" && payload != conn.scheme.ID() {
+			return nil, false, ErrUnsupportedPuzzleScheme
+		}
+
+		return conn.scheme, false, nil
+	}
+
+	legacy = payload == ""
+
+	peerSchemes := puzzle.SplitIDs(payload)
+	if legacy {
+		peerSchemes = []string{puzzle.SchemeHashcash}
+	}
+
+	scheme, err = s.schemes.Negotiate(peerSchemes)
+	if err != nil {
+		return nil, false, ErrUnsupportedPuzzleScheme
+	}
+
+	return scheme, legacy, nil
+}
+
+func (s *Server) responseResource(conn *connection, payload string) {
 	const operationName = "service.Server.responseResource"
 
+	clientID, w := conn.clientID, conn.rw
+
 	s.logger.Info("requested resource", "clientID", clientID, "solution", payload)
 
-	mainHashcash, err := hashcash.ParseHeader(payload)
+	scheme, serialized, err := s.solutionScheme(conn, payload)
+	if err != nil {
+		s.logger.Info(err.Error(), "clientID", clientID, "header", payload)
+		s.writeError(clientID, err, w)
+
+		return
+	}
+
+	mainPuzzle, err := scheme.Parse(serialized)
 	if err != nil {
 		s.logger.Info(ErrHashcashHeaderNotCorrect.Error(), "clientID", clientID, "header", payload)
 		s.writeError(clientID, ErrHashcashHeaderNotCorrect, w)
@@ -128,28 +366,52 @@ func (s *Server) responseResource(clientID, payload string, w io.Writer) {
 		return
 	}
 
-	if _, ok := s.puzzleCache.Get(mainHashcash.Key()); !ok {
+	// Server clock could be stepped back or forth between issue and verification, e.g. by NTP,
+	// date up to skew in the future and expiration up to skew ago are tolerated.
+	now, skew := s.clock.Now().UTC(), s.config.PuzzleClockSkew()
+
+	// Cheap checks go before cache lookup and hashing.
+	if err = mainPuzzle.Precheck(now.Add(skew)); err != nil {
+		clientErr := precheckError(err)
+		s.logger.Info(clientErr.Error(), "clientID", clientID, "header", payload)
+		s.writeError(clientID, clientErr, w)
+
+		return
+	}
+
+	cacheKey := puzzleCacheKey(scheme, mainPuzzle)
+
+	issuedDifficulty, ok := s.puzzleCache.Get(cacheKey)
+	if !ok {
 		s.logger.Info(ErrHashcashHeaderNotFound.Error(), "clientID", clientID, "header", payload)
 		s.writeError(clientID, ErrHashcashHeaderNotFound, w)
 
 		return
 	}
 
-	if !mainHashcash.EqualResource(clientID) {
+	if mainPuzzle.Difficulty() != issuedDifficulty {
+		s.logger.Info(ErrPuzzleDifficultyMismatch.Error(), "clientID", clientID, "header", payload,
+			"issued", issuedDifficulty, "claimed", mainPuzzle.Difficulty())
+		s.writeError(clientID, ErrPuzzleDifficultyMismatch, w)
+
+		return
+	}
+
+	if !mainPuzzle.EqualResource(clientID) {
 		s.logger.Info(ErrHashcashHeaderNotFound.Error(), "clientID", clientID, "header", payload)
 		s.writeError(clientID, ErrHashcashHeaderNotFound, w)
 
 		return
 	}
 
-	if !mainHashcash.IsActual(s.config.PuzzleTTL()) {
+	if !mainPuzzle.IsActual(now.Add(-skew), s.config.PuzzleTTL()) {
 		s.logger.Info(ErrHashcashExpirationExceeded.Error(), "clientID", clientID, "header", payload)
 		s.writeError(clientID, ErrHashcashExpirationExceeded, w)
 
 		return
 	}
 
-	isHashCorrect, err := mainHashcash.Header().IsHashCorrect(mainHashcash.Bits())
+	isHashCorrect, err := s.verify(mainPuzzle)
 	if err != nil {
 		s.logger.Error(err.Error(), "op", operationName, "clientID", clientID)
 		s.writeError(clientID, ErrInternalError, w)
@@ -178,10 +440,52 @@ func (s *Server) responseResource(clientID, payload string, w io.Writer) {
 	}
 
 	s.writeMsg(clientID, msg, w)
-	s.puzzleCache.Delete(mainHashcash.Key())
+	s.puzzleCache.Delete(cacheKey)
 	s.logger.Info("resource sent", "clientID", clientID, "resource", msg.Payload)
 }
 
+// solutionScheme - returns scheme and serialized puzzle from solution payload.
+// Legacy clients send hashcash header without scheme id,
+// it's rejected with ErrUnsupportedPuzzleScheme if hashcash scheme isn't registered.
+// After handshake solution of other scheme than picked one is rejected too.
+func (s *Server) solutionScheme(conn *connection, payload string) (puzzle.Scheme, string, error) {
+	scheme, serialized := puzzle.Scheme(nil), payload
+
+	if id, unwrapped, err := puzzle.Unwrap(payload); err == nil {
+		if registered, ok := s.schemes.Get(id); ok {
+			scheme, serialized = registered, unwrapped
+		}
+	}
+
+	if scheme == nil {
+		hashcashScheme, ok := s.schemes.Get(puzzle.SchemeHashcash)
+		if !ok {
+			return nil, "", ErrUnsupportedPuzzleScheme
+		}
+
+		scheme = hashcashScheme
+	}
+
+	if conn.scheme != nil && scheme.ID() != conn.scheme.ID() {
+		return nil, "", ErrUnsupportedPuzzleScheme
+	}
+
+	return scheme, serialized, nil
+}
+
+func (s *Server) verify(mainPuzzle puzzle.Puzzle) (bool, error) {
+	s.verifyLimiter <- struct{}{}
+	defer func() { <-s.verifyLimiter }()
+
+	return mainPuzzle.Verify() //nolint:wrapcheck // puzzle error.
+}
+
+// puzzleCacheKey - puzzle issue key prefixed by scheme id, so puzzles of different schemes never match.
+// Issue key doesn't contain claimed difficulty, so lowered difficulty is found and rejected as mismatch.
+func puzzleCacheKey(scheme puzzle.Scheme, p puzzle.Puzzle) string {
+	return scheme.ID() + puzzle.DelimiterScheme + p.IssueKey()
+}
+
 func (s *Server) randomResource() (string, error) {
 	keys := s.resourceCache.Keys()
 	if len(keys) == 0 {
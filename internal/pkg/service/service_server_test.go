@@ -0,0 +1,659 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/cache"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/clock"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/hashcash"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/puzzle"
+)
+
+const testClientID = "127.0.0.1:1234"
+
+type mockLogger struct{}
+
+func (l *mockLogger) Info(_ string, _ ...any)  {}
+func (l *mockLogger) Error(_ string, _ ...any) {}
+
+type mockConfig struct {
+	bits int
+	skew time.Duration
+}
+
+func (c *mockConfig) PuzzleTTL() time.Duration            { return time.Minute }
+func (c *mockConfig) PuzzleClockSkew() time.Duration      { return c.skew }
+func (c *mockConfig) PuzzleVerifyConcurrency() int        { return 1 }
+func (c *mockConfig) MaxPuzzlesPerConnection() int        { return 2 }
+func (c *mockConfig) PuzzleZeroBits() int                 { return c.bits }
+func (c *mockConfig) PuzzleAlgorithm() hashcash.Algorithm { return hashcash.SHA256{} }
+
+// mockPuzzleCache - puzzle cache shared by connections handled concurrently.
+type mockPuzzleCache struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+func newMockPuzzleCache() *mockPuzzleCache {
+	return &mockPuzzleCache{values: make(map[string]int)}
+}
+
+func (c *mockPuzzleCache) AddWithExp(k string, v int, _ time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[k] = v
+}
+
+func (c *mockPuzzleCache) Delete(k string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.values, k)
+}
+
+func (c *mockPuzzleCache) Get(k string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.values[k]
+
+	return v, ok
+}
+
+func (c *mockPuzzleCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.values)
+}
+
+type mockResourceCache struct{}
+
+func (c *mockResourceCache) Get(_ int) (string, bool) { return "resource", true }
+func (c *mockResourceCache) Keys() []int              { return []int{0} }
+
+type mockErrorChecker struct{}
+
+func (c *mockErrorChecker) IsTimeout(_ error) bool { return false }
+func (c *mockErrorChecker) IsClosed(_ error) bool  { return false }
+
+func newTestServer(config *mockConfig) (*Server, *mockPuzzleCache) {
+	puzzleCache := newMockPuzzleCache()
+
+	return NewServer(&ServerOpts{
+		Logger:        &mockLogger{},
+		Config:        config,
+		PuzzleCache:   puzzleCache,
+		ResourceCache: &mockResourceCache{},
+		ErrorChecker:  &mockErrorChecker{},
+		Schemes:       puzzle.NewRegistry(puzzle.NewHashcashScheme(config, nil)),
+	}), puzzleCache
+}
+
+// issue - request puzzle and returns received hashcash.
+func issue(t *testing.T, srv *Server) *hashcash.Hashcash {
+	t.Helper()
+
+	var w bytes.Buffer
+	srv.responsePuzzle(srv.newConnection(testClientID, &w), "")
+
+	msg, err := message.ParseMessage(w.String())
+	require.NoError(t, err)
+	require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+
+	h, err := hashcash.ParseHeader(msg.Payload)
+	require.NoError(t, err)
+
+	return h
+}
+
+// submit - send solution and returns response message.
+func submit(t *testing.T, srv *Server, header string) message.Message {
+	t.Helper()
+
+	var w bytes.Buffer
+	srv.responseResource(srv.newConnection(testClientID, &w), header)
+
+	msg, err := message.ParseMessage(w.String())
+	require.NoError(t, err)
+
+	return msg
+}
+
+func Test_ResponseResource(t *testing.T) {
+	t.Run("solution accepted", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		h := issue(t, srv)
+		require.NoError(t, h.Compute(1000))
+
+		msg := submit(t, srv, string(h.Header()))
+		require.Equal(t, message.CommandResponseResource, msg.Command)
+		require.Equal(t, "resource", msg.Payload)
+	})
+
+	t.Run("prechecks rejected", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		h := issue(t, srv)
+		header := string(h.Header())
+		parts := strings.Split(header, ":")
+		date, rand, counter := parts[2], parts[len(parts)-2], parts[len(parts)-1]
+
+		for _, tc := range []struct {
+			header string
+			err    error
+		}{
+			{
+				header: strings.Replace(header, testClientID, strings.Repeat("a", hashcash.MaxResourceLength+1), 1),
+				err:    ErrPuzzleResourceTooLong,
+			},
+			{
+				header: strings.TrimSuffix(header, counter) + base64.StdEncoding.EncodeToString([]byte("2147483648")),
+				err:    ErrPuzzleCounterTooLarge,
+			},
+			{
+				header: strings.Replace(header, date, time.Now().UTC().Add(time.Hour).Format("20060102150405"), 1),
+				err:    ErrPuzzleDateInFuture,
+			},
+			{
+				header: strings.Replace(header, ":"+rand+":", ":AQ==:", 1),
+				err:    ErrPuzzleRandNotCorrect,
+			},
+		} {
+			msg := submit(t, srv, tc.header)
+			require.Equal(t, errorMessage(tc.err), msg, tc.header)
+		}
+	})
+
+	t.Run("lower claimed bits rejected before verify", func(t *testing.T) {
+		srv, puzzleCache := newTestServer(&mockConfig{bits: 2})
+
+		h := issue(t, srv)
+
+		// forged stamp is solved for lowered bits, so it would pass verify.
+		forged, err := hashcash.ParseHeader(strings.Replace(string(h.Header()), "1:2:", "1:1:", 1))
+		require.NoError(t, err)
+		require.NoError(t, forged.Compute(1000))
+
+		ok, err := forged.IsSolved()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		msg := submit(t, srv, string(forged.Header()))
+		require.Equal(t, errorMessage(ErrPuzzleDifficultyMismatch), msg)
+		require.Equal(t, 1, puzzleCache.Len())
+
+		require.NoError(t, h.Compute(100000))
+
+		msg = submit(t, srv, string(h.Header()))
+		require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+	})
+
+	t.Run("legacy solution rejected without hashcash scheme", func(t *testing.T) {
+		config := &mockConfig{bits: 1}
+		srv := NewServer(&ServerOpts{
+			Logger:        &mockLogger{},
+			Config:        config,
+			PuzzleCache:   newMockPuzzleCache(),
+			ResourceCache: &mockResourceCache{},
+			ErrorChecker:  &mockErrorChecker{},
+			Schemes:       puzzle.NewRegistry(puzzle.NewSubPuzzleScheme(nil)),
+		})
+
+		msg := submit(t, srv, "1:1:20231102192537:resource::AQ==:MA==")
+		require.Equal(t, errorMessage(ErrUnsupportedPuzzleScheme), msg)
+	})
+}
+
+func Test_ClockSkew(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name    string
+		skew    time.Duration
+		advance time.Duration
+		err     error
+	}{
+		{name: "dated ahead rejected", advance: -30 * time.Second, err: ErrPuzzleDateInFuture},
+		{name: "dated ahead within skew accepted", skew: time.Minute, advance: -30 * time.Second},
+		{name: "dated ahead beyond skew rejected", skew: 10 * time.Second, advance: -30 * time.Second,
+			err: ErrPuzzleDateInFuture},
+		{name: "expired rejected", advance: time.Minute + 10*time.Second, err: ErrHashcashHeaderNotFound},
+		{name: "expired within skew accepted", skew: time.Minute, advance: time.Minute + 10*time.Second},
+		{name: "expired beyond skew rejected", skew: 5 * time.Second, advance: time.Minute + 10*time.Second,
+			err: ErrHashcashHeaderNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := newTestServer(&mockConfig{bits: 1, skew: tc.skew})
+
+			fakeClock := clock.NewFake(start)
+			srv.clock = fakeClock
+			srv.puzzleCache = cache.New[string, int](context.Background(), cache.Opts{Clock: fakeClock})
+
+			h := issue(t, srv)
+			require.Equal(t, start, h.Date())
+			require.NoError(t, h.Compute(1000))
+
+			fakeClock.Advance(tc.advance)
+
+			msg := submit(t, srv, string(h.Header()))
+			if tc.err != nil {
+				require.Equal(t, errorMessage(tc.err), msg)
+
+				return
+			}
+
+			require.Equal(t, message.CommandResponseResource, msg.Command, msg.Payload)
+		})
+	}
+}
+
+type mockClientConfig struct{}
+
+func (c *mockClientConfig) PuzzleComputeMaxAttempts() int { return 1000000 }
+
+// pipeConn - client side of connection handled by server.
+type pipeConn struct {
+	net.Conn
+	handled chan struct{}
+}
+
+// Hangup - close connection and wait until server handler returns.
+func (c *pipeConn) Hangup() {
+	c.Close()
+	<-c.handled
+}
+
+// dial - returns client side of connection handled by server.
+func dial(t *testing.T, srv *Server) *pipeConn {
+	t.Helper()
+
+	client, server := net.Pipe()
+	conn := &pipeConn{Conn: client, handled: make(chan struct{})}
+
+	t.Cleanup(conn.Hangup)
+
+	go func() {
+		defer close(conn.handled)
+		defer server.Close()
+
+		srv.HandleMessages(testClientID, server)
+	}()
+
+	return conn
+}
+
+// exchange - send message and returns response message.
+func exchange(t *testing.T, conn net.Conn, msg message.Message) message.Message {
+	t.Helper()
+
+	_, err := conn.Write(msg.Bytes())
+	require.NoError(t, err)
+
+	raw, err := bufio.NewReader(conn).ReadString(message.DelimiterMessage)
+	require.NoError(t, err)
+
+	res, err := message.ParseMessage(raw)
+	require.NoError(t, err)
+
+	return res
+}
+
+func Test_Handshake(t *testing.T) {
+	t.Run("resource received after handshake", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		for _, handshake := range []bool{true, false} {
+			client := NewClient(ClientOpts{
+				Logger:    &mockLogger{},
+				Config:    &mockClientConfig{},
+				Schemes:   puzzle.NewRegistry(puzzle.NewSubPuzzleScheme(nil), puzzle.NewHashcashScheme(nil, nil)),
+				Handshake: handshake,
+			})
+
+			conn := dial(t, srv)
+
+			resource, err := client.RequestResource(testClientID, conn)
+			require.NoError(t, err)
+			require.Equal(t, "resource", resource)
+
+			conn.Hangup()
+		}
+	})
+
+	t.Run("client solves puzzle of legacy server", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		conn, server := net.Pipe()
+		t.Cleanup(func() { conn.Close() })
+
+		// Legacy server sends hashcash header without scheme id and expects it back the same way.
+		go func() {
+			defer server.Close()
+
+			r := bufio.NewReader(server)
+			if _, err := r.ReadString(message.DelimiterMessage); err != nil {
+				return
+			}
+
+			conn := srv.newConnection(testClientID, server)
+			srv.responsePuzzle(conn, "")
+
+			raw, err := r.ReadString(message.DelimiterMessage)
+			if err != nil {
+				return
+			}
+
+			if msg, err := message.ParseMessage(raw); err == nil && !strings.HasPrefix(msg.Payload, puzzle.SchemeHashcash) {
+				srv.responseResource(conn, msg.Payload)
+			}
+		}()
+
+		client := NewClient(ClientOpts{
+			Logger:  &mockLogger{},
+			Config:  &mockClientConfig{},
+			Schemes: puzzle.NewRegistry(puzzle.NewHashcashScheme(nil, nil)),
+		})
+
+		resource, err := client.RequestResource(testClientID, conn)
+		require.NoError(t, err)
+		require.Equal(t, "resource", resource)
+	})
+
+	t.Run("server picks supported values", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		msg := exchange(t, dial(t, srv), message.Message{
+			Command: message.CommandHello,
+			Payload: "versions=2,1;schemes=subpuzzle,hashcash;codecs=binary,text;compression=zstd",
+		})
+		require.Equal(t, message.Message{
+			Command: message.CommandWelcome,
+			Payload: "versions=1;schemes=hashcash;codecs=text",
+		}, msg)
+	})
+
+	t.Run("legacy client without handshake", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		msg := exchange(t, dial(t, srv), message.Message{Command: message.CommandRequestPuzzle})
+		require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+
+		_, err := hashcash.ParseHeader(msg.Payload)
+		require.NoError(t, err)
+	})
+
+	t.Run("handshake failed", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+
+		for payload, expected := range map[string]error{
+			"versions=2;schemes=hashcash;codecs=text": ErrUnsupportedProtocolVersion,
+			"versions=1;schemes=hashcash;codecs=json": ErrUnsupportedCodec,
+			"versions=1;schemes=timelock;codecs=text": ErrUnsupportedPuzzleScheme,
+			"versions": ErrIncorrectMessageFormat,
+		} {
+			msg := exchange(t, dial(t, srv), message.Message{Command: message.CommandHello, Payload: payload})
+			require.Equal(t, errorMessage(expected), msg, payload)
+		}
+	})
+
+	t.Run("only picked scheme is used after handshake", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		srv.schemes = puzzle.NewRegistry(stubScheme{}, puzzle.NewHashcashScheme(&mockConfig{bits: 1}, nil))
+		conn := dial(t, srv)
+
+		msg := exchange(t, conn, message.Message{Command: message.CommandHello, Payload: "versions=1;schemes=hashcash;codecs=text"})
+		require.Equal(t, message.Message{Command: message.CommandWelcome, Payload: "versions=1;schemes=hashcash;codecs=text"}, msg)
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle, Payload: "stub"})
+		require.Equal(t, errorMessage(ErrUnsupportedPuzzleScheme), msg)
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle})
+		require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+		require.True(t, strings.HasPrefix(msg.Payload, puzzle.SchemeHashcash+puzzle.DelimiterScheme), msg.Payload)
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandRequestResource, Payload: "stub k+"})
+		require.Equal(t, errorMessage(ErrUnsupportedPuzzleScheme), msg)
+	})
+
+	t.Run("handshake is allowed only first", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		conn := dial(t, srv)
+
+		msg := exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle, Payload: puzzle.SchemeHashcash})
+		require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandHello, Payload: "versions=1"})
+		require.Equal(t, errorMessage(ErrHandshakeNotFirst), msg)
+	})
+}
+
+func Test_HandleMessages(t *testing.T) {
+	t.Run("unsupported command doesn't close connection", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		conn := dial(t, srv)
+
+		msg := exchange(t, conn, message.Message{Command: 42, Payload: "ping"})
+		require.Equal(t, errorMessage(ErrUnsupportedCommand), msg)
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle, Payload: puzzle.SchemeHashcash})
+		require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+	})
+
+	t.Run("unsupported commands over limit close connection", func(t *testing.T) {
+		penalties := mockPenalties{}
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		srv.penalties = penalties
+		conn := dial(t, srv)
+
+		for range maxUnsupportedCommands {
+			msg := exchange(t, conn, message.Message{Command: 42, Payload: "ping"})
+			require.Equal(t, errorMessage(ErrUnsupportedCommand), msg)
+		}
+
+		msg := exchange(t, conn, message.Message{Command: 42, Payload: "ping"})
+		require.Equal(t, errorMessage(ErrUnsupportedCommandsLimit), msg)
+
+		_, err := bufio.NewReader(conn).ReadString(message.DelimiterMessage)
+		require.ErrorIs(t, err, io.EOF)
+
+		conn.Hangup()
+		require.Equal(t, mockPenalties{ErrUnsupportedCommandsLimit.Error(): 1}, penalties)
+	})
+
+	t.Run("incorrect message format closes connection", func(t *testing.T) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		conn := dial(t, srv)
+
+		_, err := conn.Write([]byte("01:\n"))
+		require.NoError(t, err)
+
+		r := bufio.NewReader(conn)
+
+		raw, err := r.ReadString(message.DelimiterMessage)
+		require.NoError(t, err)
+		require.Equal(t, errorMessage(ErrIncorrectMessageFormat).String(), raw)
+
+		_, err = r.ReadString(message.DelimiterMessage)
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
+
+type mockPenalties map[string]int
+
+func (p mockPenalties) Violation(_, reason string) (int, bool) {
+	p[reason]++
+
+	return p[reason], false
+}
+
+func Test_ProtocolViolation(t *testing.T) {
+	t.Run("server commands from client rejected", func(t *testing.T) {
+		penalties := mockPenalties{}
+		srv, puzzleCache := newTestServer(&mockConfig{bits: 1})
+		srv.penalties = penalties
+
+		h := issue(t, srv)
+		require.NoError(t, h.Compute(1000))
+
+		for _, command := range []message.Command{
+			message.CommandError, message.CommandResponsePuzzle, message.CommandResponseResource, message.CommandWelcome,
+		} {
+			conn := dial(t, srv)
+			msg := exchange(t, conn, message.Message{Command: command, Payload: string(h.Header())})
+			require.Equal(t, errorMessage(ErrProtocolViolation), msg, command)
+			conn.Hangup()
+		}
+
+		require.Equal(t, mockPenalties{ErrProtocolViolation.Error(): 4}, penalties)
+		require.Equal(t, 1, puzzleCache.Len(), "solved puzzle isn't spent")
+	})
+
+	t.Run("illegal transitions rejected", func(t *testing.T) {
+		penalties := mockPenalties{}
+		srv, puzzleCache := newTestServer(&mockConfig{bits: 1})
+		srv.penalties = penalties
+
+		h := issue(t, srv)
+		require.NoError(t, h.Compute(1000))
+
+		conn := dial(t, srv)
+		msg := exchange(t, conn, message.Message{Command: message.CommandRequestResource, Payload: string(h.Header())})
+		require.Equal(t, errorMessage(ErrPuzzleNotRequested), msg)
+		conn.Hangup()
+		require.Equal(t, 1, puzzleCache.Len(), "solved puzzle isn't spent")
+
+		conn = dial(t, srv)
+		for range 2 {
+			msg = exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle, Payload: puzzle.SchemeHashcash})
+			require.Equal(t, message.CommandResponsePuzzle, msg.Command)
+		}
+
+		msg = exchange(t, conn, message.Message{Command: message.CommandRequestPuzzle, Payload: puzzle.SchemeHashcash})
+		require.Equal(t, errorMessage(ErrPuzzleLimitExceeded), msg)
+		conn.Hangup()
+
+		require.Equal(t, mockPenalties{ErrPuzzleNotRequested.Error(): 1, ErrPuzzleLimitExceeded.Error(): 1}, penalties)
+	})
+}
+
+// stubScheme - scheme with puzzles solved by adding "+" to serialized puzzle, fuzz tests can solve them.
+type stubScheme struct{}
+
+type stubPuzzle struct {
+	solved bool
+}
+
+func (stubScheme) ID() string { return "stub" }
+
+func (stubScheme) Issue(_ string, _ time.Time) (puzzle.Puzzle, error) { return &stubPuzzle{}, nil }
+
+func (stubScheme) Parse(serialized string) (puzzle.Puzzle, error) {
+	switch serialized {
+	case "k":
+		return &stubPuzzle{}, nil
+	case "k+":
+		return &stubPuzzle{solved: true}, nil
+	default:
+		return nil, puzzle.ErrIncorrectEnvelope
+	}
+}
+
+func (p *stubPuzzle) Key() string                                { return "k" }
+func (p *stubPuzzle) IssueKey() string                           { return "k" }
+func (p *stubPuzzle) EqualResource(_ string) bool                { return true }
+func (p *stubPuzzle) IsActual(_ time.Time, _ time.Duration) bool { return true }
+func (p *stubPuzzle) Difficulty() int                            { return 1 }
+func (p *stubPuzzle) Precheck(_ time.Time) error                 { return nil }
+func (p *stubPuzzle) Verify() (bool, error)                      { return p.solved, nil }
+
+func (p *stubPuzzle) Serialize() string {
+	if p.solved {
+		return "k+"
+	}
+
+	return "k"
+}
+
+func (p *stubPuzzle) Solve(_ int, _ puzzle.ProgressFunc) error {
+	p.solved = true
+
+	return nil
+}
+
+// FuzzHandleMessages - resource is sent only in response to a solution of an issued puzzle.
+// Every message gets exactly one response, so i-th response line answers i-th message line.
+func FuzzHandleMessages(f *testing.F) {
+	for _, seed := range []string{
+		"1:stub\n3:stub k+\n",
+		"5:versions=1;schemes=stub;codecs=text\n1:stub\n3:stub k+\n",
+		"3:stub k+\n",
+		"1:stub\n3:stub k\n",
+		"1:stub\n1:stub\n3:stub k+\n3:stub k+\n",
+		"1:stub\n2:stub k+\n",
+		"1:stub\n0:stub k+\n",
+		"1:stub\n4:stub k+\n",
+		"1:stub\n42:ping\n3:stub k+\n",
+		"1:\n3:1:1:20231102192537:resource::Cxphfw==:MA==\n",
+		"5:versions=1\n5:versions=1\n",
+		"01:stub\n",
+		"1:stub",
+		"\n\n",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		srv, _ := newTestServer(&mockConfig{bits: 1})
+		srv.schemes = puzzle.NewRegistry(stubScheme{}, puzzle.NewHashcashScheme(&mockConfig{bits: 1}, nil))
+		srv.penalties = mockPenalties{}
+
+		var out bytes.Buffer
+		srv.HandleMessages(testClientID, struct {
+			io.Reader
+			io.Writer
+		}{strings.NewReader(input), &out})
+
+		lines := strings.SplitAfter(input, "\n")
+		responses := strings.SplitAfter(out.String(), "\n")
+		issued := false
+
+		for i, raw := range responses {
+			if raw == "" {
+				continue
+			}
+
+			res, err := message.ParseMessage(raw)
+			require.NoError(t, err, raw)
+			require.Less(t, i, len(lines), "response without request")
+
+			req, err := message.ParseMessage(lines[i])
+
+			switch res.Command {
+			case message.CommandResponsePuzzle:
+				require.NoError(t, err)
+				require.Equal(t, message.CommandRequestPuzzle, req.Command)
+
+				issued = true
+			case message.CommandResponseResource:
+				require.NoError(t, err)
+				require.True(t, issued, "resource without issued puzzle")
+				require.Equal(t, message.Message{Command: message.CommandRequestResource, Payload: "stub k+"}, req)
+				require.Len(t, responses, i+2, "connection isn't closed after resource")
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+)
+
+// connState - connection state.
+type connState int8
+
+const (
+	// stateNew - client connected, nothing received yet.
+	stateNew connState = iota
+
+	// stateGreeted - handshake completed.
+	stateGreeted
+
+	// statePuzzleIssued - client requested a puzzle.
+	statePuzzleIssued
+
+	// stateDone - client requested resource, connection is closing.
+	stateDone
+)
+
+// String - returns state name.
+func (s connState) String() string {
+	switch s {
+	case stateNew:
+		return "new"
+	case stateGreeted:
+		return "greeted"
+	case statePuzzleIssued:
+		return "puzzle issued"
+	case stateDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// transitions - legal transitions, next state by current state and received command.
+var transitions = map[connState]map[message.Command]connState{ //nolint:gochecknoglobals // constant.
+	stateNew: {
+		message.CommandHello:         stateGreeted,
+		message.CommandRequestPuzzle: statePuzzleIssued,
+	},
+	stateGreeted: {
+		message.CommandRequestPuzzle: statePuzzleIssued,
+	},
+	statePuzzleIssued: {
+		message.CommandRequestPuzzle:   statePuzzleIssued,
+		message.CommandRequestResource: stateDone,
+	},
+}
+
+// stateMachine - connection protocol flow: optional handshake, puzzle requests, resource request.
+// maxPuzzles - max number of puzzle requests per connection, 0 - unlimited.
+type stateMachine struct {
+	state      connState
+	puzzles    int
+	maxPuzzles int
+}
+
+// transition - move to the next state by received command.
+// Returns error and keeps state if transition is illegal.
+func (m *stateMachine) transition(command message.Command) error {
+	next, ok := transitions[m.state][command]
+	if !ok {
+		return m.illegal(command)
+	}
+
+	if command == message.CommandRequestPuzzle {
+		if m.maxPuzzles > 0 && m.puzzles >= m.maxPuzzles {
+			return ErrPuzzleLimitExceeded
+		}
+
+		m.puzzles++
+	}
+
+	m.state = next
+
+	return nil
+}
+
+// illegal - returns error of illegal transition.
+func (m *stateMachine) illegal(command message.Command) error {
+	switch {
+	case command == message.CommandHello:
+		return ErrHandshakeNotFirst
+	case command == message.CommandRequestResource && m.state != stateDone:
+		return ErrPuzzleNotRequested
+	default:
+		return ErrUnexpectedCommand
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kamilkn/pow-tcp-server-client/internal/pkg/lib/message"
+)
+
+func Test_StateMachine(t *testing.T) {
+	t.Run("legal flows", func(t *testing.T) {
+		for _, flow := range [][]message.Command{
+			{message.CommandRequestPuzzle, message.CommandRequestResource},
+			{message.CommandHello, message.CommandRequestPuzzle, message.CommandRequestResource},
+			{message.CommandHello, message.CommandRequestPuzzle, message.CommandRequestPuzzle, message.CommandRequestResource},
+		} {
+			m := stateMachine{maxPuzzles: 2}
+
+			for _, command := range flow {
+				require.NoError(t, m.transition(command), flow)
+			}
+
+			require.Equal(t, stateDone, m.state)
+		}
+	})
+
+	t.Run("illegal transitions", func(t *testing.T) {
+		for _, tc := range []struct {
+			flow []message.Command
+			err  error
+		}{
+			{[]message.Command{message.CommandRequestResource}, ErrPuzzleNotRequested},
+			{[]message.Command{message.CommandHello, message.CommandRequestResource}, ErrPuzzleNotRequested},
+			{[]message.Command{message.CommandHello, message.CommandHello}, ErrHandshakeNotFirst},
+			{[]message.Command{message.CommandRequestPuzzle, message.CommandHello}, ErrHandshakeNotFirst},
+			{[]message.Command{message.CommandRequestPuzzle, message.CommandRequestPuzzle, message.CommandRequestPuzzle},
+				ErrPuzzleLimitExceeded},
+			{[]message.Command{message.CommandRequestPuzzle, message.CommandRequestResource, message.CommandRequestResource},
+				ErrUnexpectedCommand},
+			{[]message.Command{message.CommandResponseResource}, ErrUnexpectedCommand},
+		} {
+			m := stateMachine{maxPuzzles: 2}
+
+			last := len(tc.flow) - 1
+			for _, command := range tc.flow[:last] {
+				require.NoError(t, m.transition(command), tc.flow)
+			}
+
+			state := m.state
+			require.ErrorIs(t, m.transition(tc.flow[last]), tc.err, tc.flow)
+			require.Equal(t, state, m.state, "state isn't changed by illegal transition")
+		}
+	})
+
+	t.Run("unlimited puzzles", func(t *testing.T) {
+		m := stateMachine{}
+
+		for range 100 {
+			require.NoError(t, m.transition(message.CommandRequestPuzzle))
+		}
+	})
+}
+
+// FuzzStateMachine - random command sequences never reach done state without a puzzle request,
+// never exceed puzzles limit and never leave done state.
+func FuzzStateMachine(f *testing.F) {
+	f.Add([]byte{1, 3})
+	f.Add([]byte{5, 1, 1, 3})
+	f.Add([]byte{1, 1, 1, 1})
+	f.Add([]byte{3, 1, 5, 0, 2, 4, 6})
+	f.Add([]byte{1, 3, 1, 3})
+
+	f.Fuzz(func(t *testing.T, commands []byte) {
+		const maxPuzzles = 3
+
+		m := stateMachine{maxPuzzles: maxPuzzles}
+		requested := false
+
+		for _, c := range commands {
+			command := message.Command(c % 8) //nolint:gomnd // built-in commands and unknown one.
+			prev := m.state
+
+			err := m.transition(command)
+			if err != nil {
+				require.Equal(t, prev, m.state)
+
+				continue
+			}
+
+			switch m.state {
+			case stateNew:
+				require.Fail(t, "transition to new state")
+			case stateGreeted:
+				require.Equal(t, stateNew, prev)
+				require.Equal(t, message.CommandHello, command)
+			case statePuzzleIssued:
+				require.Equal(t, message.CommandRequestPuzzle, command)
+
+				requested = true
+			case stateDone:
+				require.NotEqual(t, stateDone, prev, "transition from done state")
+				require.True(t, requested, "resource without puzzle")
+			}
+
+			require.LessOrEqual(t, m.puzzles, maxPuzzles)
+		}
+	})
+}